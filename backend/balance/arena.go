@@ -0,0 +1,213 @@
+package balance
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ArenaTeamSize はアリーナの1チームの人数（16人なら8チーム）
+const ArenaTeamSize = 2
+
+// ArenaMaxPlayers はアリーナのロビーの最大人数
+const ArenaMaxPlayers = 16
+
+// maxArenaIterations は入れ替えの最大回数（16人なら通常は数十回で収束する）
+const maxArenaIterations = 1000
+
+// ArenaOptions はN人×Kチームへのチーム分けのオプション
+type ArenaOptions struct {
+	// TeamCount はチーム数（省略時はプレイヤー数 / TeamSize）
+	TeamCount int `json:"teamCount,omitempty"`
+	// TeamSize は1チームの人数（デフォルト: 2）
+	TeamSize int `json:"teamSize,omitempty"`
+	// GameMode が"ARAM"の場合はARAMのレーティングを使用する
+	GameMode string `json:"gameMode,omitempty"`
+	// Seed は同じレーティングのプレイヤーの並び順に使う乱数のシード（0の場合はランダム）
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// ArenaTeam はN人×Kチーム分けの1チーム
+type ArenaTeam struct {
+	Number        int      `json:"number"`        // チーム番号（1始まり）
+	Players       []Player `json:"players"`       // 所属プレイヤー
+	TotalRating   int      `json:"totalRating"`   // レーティング合計
+	AverageRating float64  `json:"averageRating"` // 平均レーティング
+}
+
+// ArenaResult はN人×Kチーム分けの結果
+type ArenaResult struct {
+	Teams      []ArenaTeam `json:"teams"`      // 全チーム（レーティング合計の高い順）
+	Spread     int         `json:"spread"`     // レーティング合計の最大と最小の差
+	StdDev     float64     `json:"stdDev"`     // レーティング合計の標準偏差
+	Iterations int         `json:"iterations"` // 採用した入れ替え回数
+	Seed       int64       `json:"seed"`       // 使用した乱数のシード
+}
+
+// DivideArena はプレイヤーをTeamSize人ずつのチームに分け、チームのレーティング合計の
+// 最大と最小の差（同じ場合は分散）が最小になるようにする。ロール配分は行わない。
+// レーティング順のスネーク配置から、改善する2チーム間の入れ替えがなくなるまで（最大maxArenaIterations回）探索する
+func DivideArena(players []Player, opts ArenaOptions) (*ArenaResult, error) {
+	size := opts.TeamSize
+	if size <= 0 {
+		size = ArenaTeamSize
+	}
+	count := opts.TeamCount
+	if count <= 0 {
+		count = len(players) / size
+	}
+	if len(players) > ArenaMaxPlayers {
+		return nil, fmt.Errorf("プレイヤーは%d人までです（現在%d人）", ArenaMaxPlayers, len(players))
+	}
+	if count < 2 {
+		return nil, fmt.Errorf("チームは2つ以上必要です（現在%d）", count)
+	}
+	if len(players) != count*size {
+		return nil, fmt.Errorf("プレイヤーは%d人必要です（%d人×%dチーム、現在%d人）",
+			count*size, size, count, len(players))
+	}
+
+	seen := make(map[string]bool, len(players))
+	for _, p := range players {
+		if p.ID == "" {
+			return nil, fmt.Errorf("プレイヤーIDが空です: %s", p.Name)
+		}
+		if seen[p.ID] {
+			return nil, fmt.Errorf("プレイヤーIDが重複しています: %s", p.ID)
+		}
+		seen[p.ID] = true
+	}
+
+	if opts.GameMode == GameModeARAM {
+		players = withAramRatings(players)
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = newSeed()
+	}
+
+	search := newArenaSearch(players, count, newRand(seed))
+	search.run()
+
+	result := &ArenaResult{Iterations: search.iterations, Seed: seed}
+	for i, members := range search.teams {
+		team := ArenaTeam{TotalRating: search.totals[i]}
+		for _, idx := range members {
+			team.Players = append(team.Players, players[idx])
+		}
+		team.AverageRating = float64(team.TotalRating) / float64(size)
+		result.Teams = append(result.Teams, team)
+	}
+
+	sort.SliceStable(result.Teams, func(a, b int) bool {
+		return result.Teams[a].TotalRating > result.Teams[b].TotalRating
+	})
+	for i := range result.Teams {
+		result.Teams[i].Number = i + 1
+	}
+
+	spread, variance := search.score()
+	result.Spread = spread
+	result.StdDev = math.Sqrt(variance / float64(count))
+
+	return result, nil
+}
+
+// arenaSearch は2チーム間のプレイヤー入れ替えによる局所探索の状態
+type arenaSearch struct {
+	players []Player
+
+	teams      [][]int // チームごとのプレイヤー番号
+	totals     []int   // チームごとのレーティング合計
+	iterations int
+}
+
+// newArenaSearch はレーティング順のスネーク配置で初期解を作る
+// 同じレーティングのプレイヤーの順番はrngで決める
+func newArenaSearch(players []Player, teamCount int, rng *rand.Rand) *arenaSearch {
+	order := rng.Perm(len(players))
+	sort.SliceStable(order, func(a, b int) bool {
+		return players[order[a]].Rating > players[order[b]].Rating
+	})
+
+	s := &arenaSearch{
+		players: players,
+		teams:   make([][]int, teamCount),
+		totals:  make([]int, teamCount),
+	}
+
+	for k, idx := range order {
+		round, pos := k/teamCount, k%teamCount
+		if round%2 == 1 {
+			pos = teamCount - 1 - pos
+		}
+		s.teams[pos] = append(s.teams[pos], idx)
+		s.totals[pos] += players[idx].Rating
+	}
+
+	return s
+}
+
+// run は評価値が最も良くなる入れ替えを、改善しなくなるまで（最大maxArenaIterations回）繰り返す
+func (s *arenaSearch) run() {
+	bestSpread, bestVariance := s.score()
+
+	for bestSpread > 0 && s.iterations < maxArenaIterations {
+		swapA, swapB, swapI, swapJ := -1, -1, -1, -1
+
+		for a := range s.teams {
+			for b := a + 1; b < len(s.teams); b++ {
+				for i, pi := range s.teams[a] {
+					for j, pj := range s.teams[b] {
+						delta := s.players[pj].Rating - s.players[pi].Rating
+						if delta == 0 {
+							continue
+						}
+
+						s.totals[a] += delta
+						s.totals[b] -= delta
+						spread, variance := s.score()
+						s.totals[a] -= delta
+						s.totals[b] += delta
+
+						if spread < bestSpread || (spread == bestSpread && variance < bestVariance) {
+							bestSpread, bestVariance = spread, variance
+							swapA, swapB, swapI, swapJ = a, b, i, j
+						}
+					}
+				}
+			}
+		}
+
+		if swapA < 0 {
+			return
+		}
+
+		pi, pj := s.teams[swapA][swapI], s.teams[swapB][swapJ]
+		delta := s.players[pj].Rating - s.players[pi].Rating
+		s.teams[swapA][swapI], s.teams[swapB][swapJ] = pj, pi
+		s.totals[swapA] += delta
+		s.totals[swapB] -= delta
+		s.iterations++
+	}
+}
+
+// score はチームのレーティング合計の最大と最小の差と、平均からの偏差の二乗和を返す
+func (s *arenaSearch) score() (spread int, variance float64) {
+	minTotal, maxTotal := s.totals[0], s.totals[0]
+	sum := 0
+	for _, total := range s.totals {
+		minTotal = min(minTotal, total)
+		maxTotal = max(maxTotal, total)
+		sum += total
+	}
+
+	mean := float64(sum) / float64(len(s.totals))
+	for _, total := range s.totals {
+		d := float64(total) - mean
+		variance += d * d
+	}
+	return maxTotal - minTotal, variance
+}
@@ -0,0 +1,79 @@
+package balance
+
+import "testing"
+
+func TestDivideArenaTeamSizes(t *testing.T) {
+	tests := []struct {
+		name      string
+		players   int
+		opts      ArenaOptions
+		wantTeams int
+		wantSize  int
+	}{
+		{"arena 8x2", 16, ArenaOptions{}, 8, 2},
+		{"team count", 12, ArenaOptions{TeamCount: 4, TeamSize: 3}, 4, 3},
+		{"size only", 9, ArenaOptions{TeamSize: 3}, 3, 3},
+		{"smallest", 4, ArenaOptions{}, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := testRoster(tt.players)
+			result, err := DivideArena(players, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Teams) != tt.wantTeams {
+				t.Fatalf("teams = %d, want %d", len(result.Teams), tt.wantTeams)
+			}
+
+			seen := make(map[string]bool)
+			for i, team := range result.Teams {
+				if len(team.Players) != tt.wantSize {
+					t.Errorf("team %d has %d players, want %d", team.Number, len(team.Players), tt.wantSize)
+				}
+				total := 0
+				for _, p := range team.Players {
+					if seen[p.ID] {
+						t.Errorf("player %s is in two teams", p.ID)
+					}
+					seen[p.ID] = true
+					total += p.Rating
+				}
+				if team.TotalRating != total {
+					t.Errorf("team %d total = %d, want %d", team.Number, team.TotalRating, total)
+				}
+				if i > 0 && team.TotalRating > result.Teams[i-1].TotalRating {
+					t.Errorf("team %d is not sorted by total rating", team.Number)
+				}
+			}
+			if len(seen) != len(players) {
+				t.Errorf("%d of %d players were placed", len(seen), len(players))
+			}
+			if result.Iterations > maxArenaIterations {
+				t.Errorf("iterations = %d, above the cap %d", result.Iterations, maxArenaIterations)
+			}
+		})
+	}
+}
+
+func TestDivideArenaRejectsRoster(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		opts    ArenaOptions
+	}{
+		{"above the lobby maximum", ArenaMaxPlayers + 2, ArenaOptions{}},
+		{"single team", 2, ArenaOptions{}},
+		{"not divisible", 7, ArenaOptions{}},
+		{"count and size mismatch", 12, ArenaOptions{TeamCount: 5, TeamSize: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DivideArena(testRoster(tt.players), tt.opts); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
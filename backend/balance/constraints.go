@@ -0,0 +1,292 @@
+package balance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraints はチーム分けの制約
+type Constraints struct {
+	Together [][2]string `json:"together,omitempty"` // 同じチームにするプレイヤーIDのペア
+	Apart    [][2]string `json:"apart,omitempty"`    // 別のチームにするプレイヤーIDのペア
+	Blue     []string    `json:"blue,omitempty"`     // チーム1（ブルーサイド）に固定するプレイヤーID
+	Red      []string    `json:"red,omitempty"`      // チーム2（レッドサイド）に固定するプレイヤーID
+}
+
+// hasConstraints は制約が1つでも指定されているか判定する
+func (c Constraints) hasConstraints() bool {
+	return len(c.Together) > 0 || len(c.Apart) > 0 || len(c.Blue) > 0 || len(c.Red) > 0
+}
+
+// ConstraintError は満たすことのできない制約の一覧
+type ConstraintError struct {
+	Conflicts []string
+}
+
+func (e *ConstraintError) Error() string {
+	return "制約を満たすチーム分けができません: " + strings.Join(e.Conflicts, "; ")
+}
+
+// constraintMasks はビットマスク判定用に変換した制約
+type constraintMasks struct {
+	together [][2]int // 同じチームにするプレイヤー番号のペア
+	apart    [][2]int // 別のチームにするプレイヤー番号のペア
+	blue     int      // チーム1に固定するプレイヤーのビット
+	red      int      // チーム2に固定するプレイヤーのビット
+}
+
+// allows はチーム1のビットマスクが制約を全て満たすか判定する
+func (c *constraintMasks) allows(mask int) bool {
+	if mask&c.blue != c.blue || mask&c.red != 0 {
+		return false
+	}
+	for _, pair := range c.together {
+		if (mask>>pair[0])&1 != (mask>>pair[1])&1 {
+			return false
+		}
+	}
+	for _, pair := range c.apart {
+		if (mask>>pair[0])&1 == (mask>>pair[1])&1 {
+			return false
+		}
+	}
+	return true
+}
+
+// compileConstraints は制約を検証してビットマスク判定用に変換する
+// 明らかに矛盾している制約はConstraintErrorとして全て列挙する
+func compileConstraints(players []Player, c Constraints) (*constraintMasks, error) {
+	index := make(map[string]int, len(players))
+	for i, p := range players {
+		index[p.ID] = i
+	}
+
+	var conflicts []string
+	lookup := func(id string) (int, bool) {
+		i, ok := index[id]
+		if !ok {
+			conflicts = append(conflicts, fmt.Sprintf("プレイヤー%sはロスターにいません", id))
+		}
+		return i, ok
+	}
+
+	masks := &constraintMasks{}
+	uf := newUnionFind(len(players))
+
+	for _, pair := range c.Together {
+		a, okA := lookup(pair[0])
+		b, okB := lookup(pair[1])
+		if okA && okB {
+			masks.together = append(masks.together, [2]int{a, b})
+			uf.union(a, b)
+		}
+	}
+	for _, pair := range c.Apart {
+		a, okA := lookup(pair[0])
+		b, okB := lookup(pair[1])
+		if !okA || !okB {
+			continue
+		}
+		if a == b || uf.find(a) == uf.find(b) {
+			conflicts = append(conflicts, fmt.Sprintf("%sと%sは同じチーム指定と別チーム指定が矛盾しています", pair[0], pair[1]))
+			continue
+		}
+		masks.apart = append(masks.apart, [2]int{a, b})
+	}
+	for _, id := range c.Blue {
+		if i, ok := lookup(id); ok {
+			masks.blue |= 1 << i
+		}
+	}
+	for _, id := range c.Red {
+		if i, ok := lookup(id); ok {
+			masks.red |= 1 << i
+		}
+	}
+
+	// 同じチームになるグループごとにサイド指定と人数をチェック
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range players {
+		root := uf.find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+	for _, root := range roots {
+		members := groups[root]
+		names := make([]string, len(members))
+		blue, red := false, false
+		for k, i := range members {
+			names[k] = players[i].ID
+			blue = blue || masks.blue&(1<<i) != 0
+			red = red || masks.red&(1<<i) != 0
+		}
+		if blue && red {
+			conflicts = append(conflicts, fmt.Sprintf("同じチーム指定の%sにブルーとレッドの両方が指定されています", strings.Join(names, ", ")))
+		}
+		if len(members) > TeamSize {
+			conflicts = append(conflicts, fmt.Sprintf("同じチーム指定の%sが%d人を超えています", strings.Join(names, ", "), TeamSize))
+		}
+	}
+
+	conflicts = append(conflicts, sideConflicts(players, uf, groups, roots, masks)...)
+
+	if len(conflicts) > 0 {
+		return nil, &ConstraintError{Conflicts: conflicts}
+	}
+	return masks, nil
+}
+
+// sideConflicts は同じチームのグループを別チーム指定でつないだまとまりごとに、
+// 2チームに分けられるか・サイド指定と矛盾しないか・各サイドの人数が収まるかをチェックする
+func sideConflicts(players []Player, uf *unionFind, groups map[int][]int, roots []int, masks *constraintMasks) []string {
+	const (
+		sideNone = iota
+		sideBlue
+		sideRed
+	)
+
+	// グループごとのサイド指定（両方指定されたグループは既に矛盾として報告済み）
+	side := make(map[int]int, len(roots))
+	for _, root := range roots {
+		for _, i := range groups[root] {
+			switch {
+			case masks.blue&(1<<i) != 0:
+				side[root] = sideBlue
+			case masks.red&(1<<i) != 0:
+				side[root] = sideRed
+			}
+		}
+	}
+
+	edges := make(map[int][]int)
+	for _, pair := range masks.apart {
+		a, b := uf.find(pair[0]), uf.find(pair[1])
+		edges[a] = append(edges[a], b)
+		edges[b] = append(edges[b], a)
+	}
+
+	ids := func(roots []int) string {
+		var names []string
+		for _, root := range roots {
+			for _, i := range groups[root] {
+				names = append(names, players[i].ID)
+			}
+		}
+		return strings.Join(names, ", ")
+	}
+
+	var conflicts []string
+	var blueRoots, redRoots []int
+	color := make(map[int]int, len(roots))
+	for _, start := range roots {
+		if _, ok := color[start]; ok {
+			continue
+		}
+
+		// 別チーム指定を辺として2色に塗り分ける
+		component := []int{start}
+		color[start] = 0
+		bipartite := true
+		for k := 0; k < len(component); k++ {
+			node := component[k]
+			for _, next := range edges[node] {
+				if c, ok := color[next]; ok {
+					bipartite = bipartite && c != color[node]
+					continue
+				}
+				color[next] = 1 - color[node]
+				component = append(component, next)
+			}
+		}
+		if !bipartite {
+			conflicts = append(conflicts, fmt.Sprintf("別チーム指定の%sが循環していて2チームに分けられません", ids(component)))
+			continue
+		}
+
+		// サイド指定から色とサイドの対応を決める（-1: 未定）
+		orientation, pinned := -1, false
+		var sizes [2]int
+		for _, node := range component {
+			sizes[color[node]] += len(groups[node])
+			if side[node] == sideNone {
+				continue
+			}
+			want := color[node] // ブルーになる色
+			if side[node] == sideRed {
+				want = 1 - color[node]
+			}
+			if orientation >= 0 && orientation != want {
+				pinned = false
+				orientation = -2
+				break
+			}
+			orientation, pinned = want, true
+		}
+		if orientation == -2 {
+			var fixed []int
+			for _, node := range component {
+				if side[node] != sideNone {
+					fixed = append(fixed, node)
+				}
+			}
+			conflicts = append(conflicts, fmt.Sprintf("別チーム指定の%sとサイド指定（%s）が矛盾しています", ids(component), ids(fixed)))
+			continue
+		}
+
+		if !pinned {
+			if max(sizes[0], sizes[1]) > TeamSize {
+				conflicts = append(conflicts, fmt.Sprintf("別チーム指定の%sで片方のチームが%d人を超えます", ids(component), TeamSize))
+			}
+			continue
+		}
+		for _, node := range component {
+			if color[node] == orientation {
+				blueRoots = append(blueRoots, node)
+			} else {
+				redRoots = append(redRoots, node)
+			}
+		}
+	}
+
+	for _, fixed := range []struct {
+		name  string
+		roots []int
+	}{{"ブルーサイド", blueRoots}, {"レッドサイド", redRoots}} {
+		count := 0
+		for _, root := range fixed.roots {
+			count += len(groups[root])
+		}
+		if count > TeamSize {
+			conflicts = append(conflicts, fmt.Sprintf("%sに固定されるプレイヤー（%s）が%d人を超えています（%d人）", fixed.name, ids(fixed.roots), TeamSize, count))
+		}
+	}
+	return conflicts
+}
+
+// unionFind は同じチームにするプレイヤーのグループを管理する
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &unionFind{parent: parent}
+}
+
+func (u *unionFind) find(x int) int {
+	for u.parent[x] != x {
+		u.parent[x] = u.parent[u.parent[x]]
+		x = u.parent[x]
+	}
+	return x
+}
+
+func (u *unionFind) union(a, b int) {
+	u.parent[u.find(a)] = u.find(b)
+}
@@ -0,0 +1,125 @@
+package balance
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompileConstraintsConflicts(t *testing.T) {
+	tests := []struct {
+		name        string
+		constraints Constraints
+		want        []string // 矛盾の説明に含まれるべき文字列（nilは矛盾なし）
+	}{
+		{
+			name:        "no conflict",
+			constraints: Constraints{Together: [][2]string{{"p0", "p1"}}, Apart: [][2]string{{"p0", "p2"}}, Blue: []string{"p0"}},
+		},
+		{
+			name:        "unknown player",
+			constraints: Constraints{Blue: []string{"p99"}},
+			want:        []string{"p99"},
+		},
+		{
+			name:        "together and apart",
+			constraints: Constraints{Together: [][2]string{{"p0", "p1"}}, Apart: [][2]string{{"p1", "p0"}}},
+			want:        []string{"p1とp0は同じチーム指定と別チーム指定が矛盾"},
+		},
+		{
+			name:        "apart pinned to same side",
+			constraints: Constraints{Apart: [][2]string{{"p0", "p1"}}, Blue: []string{"p0", "p1"}},
+			want:        []string{"p0", "p1", "サイド指定"},
+		},
+		{
+			name: "apart pinned to same side through together group",
+			constraints: Constraints{
+				Together: [][2]string{{"p0", "p2"}},
+				Apart:    [][2]string{{"p2", "p1"}},
+				Red:      []string{"p0", "p1"},
+			},
+			want: []string{"p0", "p1", "p2", "サイド指定"},
+		},
+		{
+			name:        "apart cycle",
+			constraints: Constraints{Apart: [][2]string{{"p0", "p1"}, {"p1", "p2"}, {"p2", "p0"}}},
+			want:        []string{"循環"},
+		},
+		{
+			name:        "blue pins over team size",
+			constraints: Constraints{Blue: []string{"p0", "p1", "p2", "p3", "p4", "p5"}},
+			want:        []string{"ブルーサイド", "6人"},
+		},
+		{
+			name: "blue side over team size after merging groups",
+			constraints: Constraints{
+				Together: [][2]string{{"p0", "p1"}, {"p1", "p2"}, {"p3", "p4"}, {"p4", "p5"}},
+				Blue:     []string{"p0", "p3"},
+			},
+			want: []string{"ブルーサイド", "p0, p1, p2", "p3, p4, p5"},
+		},
+		{
+			name: "red side over team size through apart",
+			constraints: Constraints{
+				Together: [][2]string{{"p1", "p2"}, {"p2", "p3"}, {"p4", "p5"}, {"p5", "p6"}},
+				Apart:    [][2]string{{"p0", "p1"}, {"p0", "p4"}},
+				Blue:     []string{"p0"},
+			},
+			want: []string{"レッドサイド", "6人"},
+		},
+		{
+			name: "unpinned apart component over team size",
+			constraints: Constraints{
+				Together: [][2]string{{"p1", "p2"}, {"p2", "p3"}, {"p4", "p5"}, {"p5", "p6"}},
+				Apart:    [][2]string{{"p0", "p1"}, {"p0", "p4"}},
+			},
+			want: []string{"片方のチーム"},
+		},
+	}
+
+	players := testRoster(10)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileConstraints(players, tt.constraints)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var constraintErr *ConstraintError
+			if !errors.As(err, &constraintErr) {
+				t.Fatalf("expected ConstraintError, got %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDivideRejectsOnlyPreviousSplit(t *testing.T) {
+	players := testRoster(10)
+	opts := Options{Constraints: Constraints{
+		Together: [][2]string{{"p0", "p1"}, {"p1", "p2"}, {"p2", "p3"}, {"p3", "p4"}},
+		Blue:     []string{"p0"},
+	}}
+
+	split, err := Divide(players, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts.PreviousTeam1 = playerIDList(split.Team1.Players)
+	_, err = Divide(players, opts)
+	if err == nil {
+		t.Fatal("expected an error when the only feasible split is the previous one")
+	}
+	var constraintErr *ConstraintError
+	if errors.As(err, &constraintErr) {
+		t.Errorf("expected a plain error, got ConstraintError: %v", err)
+	}
+}
@@ -0,0 +1,402 @@
+package balance
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// チーム分けのモード
+const (
+	ModeRating  = "rating"  // レーティング合計の差を最小化（デフォルト）
+	ModeLane    = "lane"    // ロール別MMRでチーム合計差と対面差を同時に最小化
+	ModeWinProb = "winprob" // 予測勝率が50%に最も近くなるように分ける
+)
+
+// ゲームモード
+const (
+	GameModeClassic = "CLASSIC" // サモナーズリフト（5ロール、デフォルト）
+	GameModeARAM    = "ARAM"    // ARAM（ロールなし、ARAMのレーティングを使用）
+	GameModeNoRole  = "NOROLE"  // その他のロールなしのモード
+)
+
+// Options はチーム分けのオプション
+type Options struct {
+	// PreviousTeam1 は前回のチーム1のプレイヤーID。指定した場合は同じ構成をスキップする
+	PreviousTeam1 []string `json:"previousTeam1,omitempty"`
+	// Mode はチーム分けのモード（"rating"、"lane"、"winprob"）
+	Mode string `json:"mode,omitempty"`
+	// LaneWeight は laneモードでの対面差合計の重み（デフォルト: 1.0）
+	LaneWeight float64 `json:"laneWeight,omitempty"`
+	// RolePenaltyWeight は laneモードでのロールペナルティ1点あたりの重み（デフォルト: 100）
+	RolePenaltyWeight float64 `json:"rolePenaltyWeight,omitempty"`
+	// GameMode はゲームモード（"CLASSIC"、"ARAM"、"NOROLE"）。ロールなしのモードではロール配分を行わない
+	GameMode string `json:"gameMode,omitempty"`
+	// WinScale は勝率予測のスケール（デフォルト: DefaultWinScale）
+	WinScale float64 `json:"winScale,omitempty"`
+	// StdDevWeight はチーム内レーティング標準偏差の差の重み（0で無効）
+	StdDevWeight float64 `json:"stdDevWeight,omitempty"`
+	// TopGapWeight は各チーム最上位プレイヤーのレーティング差の重み（0で無効）
+	TopGapWeight float64 `json:"topGapWeight,omitempty"`
+	// TopK は返すチーム分けの候補数（デフォルト: 1）
+	TopK int `json:"topK,omitempty"`
+	// MinDistance は候補同士で所属チームが異なるプレイヤーの最小人数
+	MinDistance int `json:"minDistance,omitempty"`
+	// TeammateHistory は過去に同じチームになったプレイヤーの組と回数
+	TeammateHistory []TeammatePair `json:"teammateHistory,omitempty"`
+	// VarietyWeight は過去の同チームの組が再び同じチームになる1回あたりのペナルティ（0で無効）
+	VarietyWeight float64 `json:"varietyWeight,omitempty"`
+	// UncertaintyWeight は各チームのレーティングの不確かさの差の重み（0で無効）
+	// 不確かなプレイヤーが片方のチームに偏ると、実際の強さの差が大きくなりやすい
+	UncertaintyWeight float64 `json:"uncertaintyWeight,omitempty"`
+	// Seed は評価値が同じ候補の順位付けと探索に使う乱数のシード（0の場合はランダムに決めて結果に返す）
+	// 同じ入力と同じシードからは常に同じチーム分けになる
+	Seed int64 `json:"seed,omitempty"`
+	// Constraints は同じチーム・別チーム・サイド固定の制約
+	Constraints
+}
+
+// withDefaults は未指定の項目にデフォルト値を設定したオプションを返す
+func (o Options) withDefaults() Options {
+	if o.Mode == "" {
+		o.Mode = ModeRating
+	}
+	if o.GameMode == "" {
+		o.GameMode = GameModeClassic
+	}
+	if o.LaneWeight <= 0 {
+		o.LaneWeight = 1.0
+	}
+	if o.RolePenaltyWeight <= 0 {
+		o.RolePenaltyWeight = 100
+	}
+	if o.WinScale <= 0 {
+		o.WinScale = DefaultWinScale
+	}
+	if o.TopK <= 0 {
+		o.TopK = 1
+	}
+	if o.Seed == 0 {
+		o.Seed = newSeed()
+	}
+	return o
+}
+
+// Split はチーム分けの結果
+type Split struct {
+	Mode           string        `json:"mode"`           // 使用したモード
+	GameMode       string        `json:"gameMode"`       // ゲームモード
+	Team1          Team          `json:"team1"`          // チーム1（ブルーサイド）
+	Team2          Team          `json:"team2"`          // チーム2（レッドサイド）
+	Diff           int           `json:"diff"`           // レーティング合計の差
+	RoleDiff       int           `json:"roleDiff"`       // 割り当てロールでのレーティング合計の差
+	LaneDiffs      []LaneDiff    `json:"laneDiffs"`      // 対面ごとのレーティング差
+	LaneGap        int           `json:"laneGap"`        // 対面差の絶対値の合計
+	Score          float64       `json:"score"`          // 評価値（小さいほど良い）
+	Distance       int           `json:"distance"`       // 最良の候補と所属チームが異なるプレイヤー数
+	Repeats        int           `json:"repeats"`        // 過去に同じチームだった組が再び同じチームになった回数
+	WinProbability WinPrediction `json:"winProbability"` // 予測勝率
+	Objectives     Objectives    `json:"objectives"`     // 評価項目ごとの値
+	Seed           int64         `json:"seed"`           // 使用した乱数のシード
+}
+
+// roleless はロール配分を行わないゲームモードか判定する
+func (o Options) roleless() bool {
+	return o.GameMode == GameModeARAM || o.GameMode == GameModeNoRole
+}
+
+// candidate は探索中のチーム分け候補
+type candidate struct {
+	mask     int     // チーム1のビットマスク
+	score    float64 // 評価値
+	perm1    []int   // チーム1のロール配分（nilならAssignRolesで決定）
+	perm2    []int   // チーム2のロール配分
+	repeats  int     // 過去の同チームの組の再会回数
+	obj      Objectives
+	roleless bool // ロール配分を行わない
+	tie      int  // 評価値が同じ候補の順位（シードから決める）
+}
+
+// Divide は10人のプレイヤーを2チームに分け、評価値が最小になる組み合わせを返す
+// players: 対象プレイヤー（10人）
+// opts: チーム分けのオプション
+func Divide(players []Player, opts Options) (*Split, error) {
+	opts.TopK = 1
+	splits, err := DivideTopK(players, opts)
+	if err != nil {
+		return nil, err
+	}
+	return splits[0], nil
+}
+
+// DivideTopK は評価値の良い順にopts.TopK件までのチーム分けを返す
+// 各候補は互いにopts.MinDistance人以上（最低1人）所属チームが異なる。
+// チームを入れ替えただけの組み合わせは同じ候補として扱う
+func DivideTopK(players []Player, opts Options) ([]*Split, error) {
+	if err := validateRoster(players); err != nil {
+		return nil, err
+	}
+
+	opts = opts.withDefaults()
+	if opts.Mode != ModeRating && opts.Mode != ModeLane && opts.Mode != ModeWinProb {
+		return nil, fmt.Errorf("不明なモードです: %s", opts.Mode)
+	}
+	if opts.GameMode != GameModeClassic && !opts.roleless() {
+		return nil, fmt.Errorf("不明なゲームモードです: %s", opts.GameMode)
+	}
+	if opts.roleless() && opts.Mode == ModeLane {
+		return nil, fmt.Errorf("%sではlaneモードを使用できません", opts.GameMode)
+	}
+	if opts.GameMode == GameModeARAM {
+		players = withAramRatings(players)
+	}
+
+	constraints, err := compileConstraints(players, opts.Constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := idKey(opts.PreviousTeam1)
+	sided := len(opts.Blue) > 0 || len(opts.Red) > 0
+	teammates := newTeammateMatrix(players, opts.TeammateHistory)
+
+	n := len(players)
+	all := 1<<n - 1
+	var candidates []candidate
+	repeated := false // 前回と同じチーム分けのために除いた組み合わせがあったか
+
+	// 全ての組み合わせを探索（10C5 = 252通り）
+	for mask := 0; mask < 1<<n; mask++ {
+		if bits.OnesCount(uint(mask)) != TeamSize {
+			continue
+		}
+
+		if !constraints.allows(mask) {
+			continue
+		}
+
+		// 前回のチーム1と同じ構成をスキップ
+		// サイド固定がない場合はチームを入れ替えただけの構成も同じチーム分けとしてスキップする
+		if previous != "" && (maskKey(players, mask) == previous ||
+			(!sided && maskKey(players, ^mask&all) == previous)) {
+			repeated = true
+			continue
+		}
+
+		cand := candidate{mask: mask, roleless: opts.roleless()}
+		team1, team2 := splitPlayers(players, mask)
+		switch opts.Mode {
+		case ModeLane:
+			cand.obj.Base, cand.perm1, cand.perm2 = bestLanePlans(team1, team2, opts)
+		case ModeWinProb:
+			// 実効レーティングの差が0のとき予測勝率がちょうど50%になる
+			cand.obj.Base = math.Abs(TeamStrength(teamRatings(team1), opts.WinScale) -
+				TeamStrength(teamRatings(team2), opts.WinScale))
+		default:
+			cand.obj.Base = float64(abs(maskRating(players, mask) - maskRating(players, ^mask&all)))
+		}
+
+		// 1人のキャリーに偏ったチームを避けるための散らばりの差
+		cand.obj.StdDevGap, cand.obj.TopGap = spreadObjectives(team1, team2)
+		cand.obj.UncertaintyGap = uncertaintyGap(team1, team2)
+
+		// 過去の同チームの組が再び同じチームになる分のペナルティ
+		cand.repeats = teammates.repeats(mask)
+
+		cand.score = cand.obj.Base +
+			opts.StdDevWeight*cand.obj.StdDevGap +
+			opts.TopGapWeight*float64(cand.obj.TopGap) +
+			opts.VarietyWeight*float64(cand.repeats) +
+			opts.UncertaintyWeight*cand.obj.UncertaintyGap
+
+		candidates = append(candidates, cand)
+	}
+
+	if len(candidates) == 0 {
+		// 制約を満たす組み合わせが前回のチーム分けしかない場合は制約の矛盾ではない
+		if repeated {
+			return nil, fmt.Errorf("前回と異なるチーム分けが見つかりません（条件を満たすのは前回のチーム分けのみです）")
+		}
+		if opts.hasConstraints() {
+			return nil, &ConstraintError{Conflicts: []string{"全ての制約を同時に満たす組み合わせが存在しません"}}
+		}
+		return nil, fmt.Errorf("条件を満たすチーム分けが見つかりません")
+	}
+
+	// 評価値が同じ候補の順位は列挙順ではなくシードで決める
+	ties := newRand(opts.Seed).Perm(len(candidates))
+	for i := range candidates {
+		candidates[i].tie = ties[i]
+	}
+
+	// 評価値の良い順に、既に選んだ候補と十分に異なるものを選ぶ
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+		return candidates[i].tie < candidates[j].tie
+	})
+
+	minDistance := max(opts.MinDistance, 1)
+	var selected []candidate
+	for _, cand := range candidates {
+		if len(selected) >= opts.TopK {
+			break
+		}
+
+		distinct := true
+		for _, s := range selected {
+			if splitDistance(cand.mask, s.mask, n) < minDistance {
+				distinct = false
+				break
+			}
+		}
+		if distinct {
+			selected = append(selected, cand)
+		}
+	}
+
+	splits := make([]*Split, len(selected))
+	for i, cand := range selected {
+		splits[i] = newSplit(players, cand)
+		splits[i].Mode = opts.Mode
+		splits[i].GameMode = opts.GameMode
+		splits[i].Seed = opts.Seed
+		splits[i].WinProbability = WinProbability(
+			teamRatings(splits[i].Team1.Players), teamRatings(splits[i].Team2.Players), opts.WinScale)
+		splits[i].Distance = splitDistance(cand.mask, selected[0].mask, n)
+	}
+	return splits, nil
+}
+
+// splitDistance は2つのチーム分けで所属チームが異なるプレイヤー数を返す
+// チーム1とチーム2を入れ替えただけの場合は0になる
+func splitDistance(a, b, n int) int {
+	changed := bits.OnesCount(uint(a ^ b))
+	return min(changed, n-changed)
+}
+
+// withAramRatings はARAMのレーティングがあるプレイヤーのRatingをそれに置き換えたコピーを返す
+func withAramRatings(players []Player) []Player {
+	result := make([]Player, len(players))
+	for i, p := range players {
+		if p.AramRating > 0 {
+			p.Rating = p.AramRating
+		}
+		result[i] = p
+	}
+	return result
+}
+
+// validateRoster はプレイヤー人数とIDの重複をチェックする
+func validateRoster(players []Player) error {
+	if len(players) != TeamSize*2 {
+		return fmt.Errorf("プレイヤーは%d人必要です（現在%d人）", TeamSize*2, len(players))
+	}
+
+	seen := make(map[string]bool, len(players))
+	for _, p := range players {
+		if p.ID == "" {
+			return fmt.Errorf("プレイヤーIDが空です: %s", p.Name)
+		}
+		if seen[p.ID] {
+			return fmt.Errorf("プレイヤーIDが重複しています: %s", p.ID)
+		}
+		seen[p.ID] = true
+	}
+
+	return nil
+}
+
+// splitPlayers はビットマスクでプレイヤーを2チームに分ける（立っているビットがチーム1）
+func splitPlayers(players []Player, mask int) (team1, team2 []Player) {
+	team1 = make([]Player, 0, TeamSize)
+	team2 = make([]Player, 0, TeamSize)
+
+	for i, p := range players {
+		if mask&(1<<i) != 0 {
+			team1 = append(team1, p)
+		} else {
+			team2 = append(team2, p)
+		}
+	}
+	return team1, team2
+}
+
+// newSplit は候補からチーム分け結果を作成する
+func newSplit(players []Player, cand candidate) *Split {
+	team1, team2 := splitPlayers(players, cand.mask)
+
+	var roles1, roles2 []RoleAssignment
+	switch {
+	case cand.roleless:
+	case cand.perm1 != nil && cand.perm2 != nil:
+		roles1 = rolesFromPerm(team1, cand.perm1)
+		roles2 = rolesFromPerm(team2, cand.perm2)
+	default:
+		roles1, _ = AssignRoles(team1)
+		roles2, _ = AssignRoles(team2)
+	}
+
+	split := &Split{
+		Team1:      newTeam(team1, roles1),
+		Team2:      newTeam(team2, roles2),
+		Score:      cand.score,
+		Repeats:    cand.repeats,
+		Objectives: cand.obj,
+	}
+	split.Diff = abs(split.Team1.TotalRating - split.Team2.TotalRating)
+	split.RoleDiff = abs(split.Team1.RoleRating - split.Team2.RoleRating)
+	split.LaneDiffs = laneDiffs(split.Team1, split.Team2)
+	for _, d := range split.LaneDiffs {
+		split.LaneGap += abs(d.Diff)
+	}
+
+	return split
+}
+
+// maskRating はビットマスクで選ばれたプレイヤーのレーティング合計を返す
+func maskRating(players []Player, mask int) int {
+	total := 0
+	for i, p := range players {
+		if mask&(1<<i) != 0 {
+			total += p.Rating
+		}
+	}
+	return total
+}
+
+// maskKey はビットマスクで選ばれたプレイヤーIDを比較用の文字列にする
+func maskKey(players []Player, mask int) string {
+	ids := make([]string, 0, TeamSize)
+	for i, p := range players {
+		if mask&(1<<i) != 0 {
+			ids = append(ids, p.ID)
+		}
+	}
+	return idKey(ids)
+}
+
+// idKey はプレイヤーIDの集合を順序に依存しない文字列にする
+func idKey(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+
+	key := ""
+	for _, id := range sorted {
+		key += id + "\x00"
+	}
+	return key
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,135 @@
+package balance
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testRoster はレーティングの異なるn人のロスターを返す
+func testRoster(n int) []Player {
+	players := make([]Player, n)
+	for i := range players {
+		players[i] = Player{
+			ID:             fmt.Sprintf("p%d", i),
+			Name:           fmt.Sprintf("Player%d", i),
+			Rating:         1000 + (i*37)%9*100,
+			PreferredRoles: []string{"FILL"},
+		}
+	}
+	return players
+}
+
+func playerIDList(players []Player) []string {
+	ids := make([]string, len(players))
+	for i, p := range players {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestRerollSkipsPreviousSplitAndMirror(t *testing.T) {
+	players := testRoster(10)
+	for seed := int64(1); seed <= 50; seed++ {
+		first, err := Divide(players, Options{Seed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		previous := idKey(playerIDList(first.Team1.Players))
+
+		reroll, err := Divide(players, Options{Seed: seed, PreviousTeam1: playerIDList(first.Team1.Players)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if idKey(playerIDList(reroll.Team1.Players)) == previous || idKey(playerIDList(reroll.Team2.Players)) == previous {
+			t.Fatalf("seed %d: reroll returned the previous split %v", seed, playerIDList(first.Team1.Players))
+		}
+	}
+}
+
+// splitKey はチームの入れ替えに依存しないチーム分けのキーを返す
+func splitKey(s *Split) string {
+	a, b := idKey(playerIDList(s.Team1.Players)), idKey(playerIDList(s.Team2.Players))
+	if a > b {
+		a, b = b, a
+	}
+	return a + "|" + b
+}
+
+// teamDistance は2つのチーム分けで所属チームが異なるプレイヤー数を返す
+func teamDistance(a, b *Split) int {
+	team1 := make(map[string]bool)
+	for _, p := range a.Team1.Players {
+		team1[p.ID] = true
+	}
+	changed := 0
+	for _, p := range b.Team1.Players {
+		if !team1[p.ID] {
+			changed++
+		}
+	}
+	return min(changed*2, TeamSize*2-changed*2)
+}
+
+func TestDivideTopKDistinct(t *testing.T) {
+	players := testRoster(10)
+	tests := []struct {
+		topK, minDistance int
+	}{
+		{5, 0},
+		{5, 2},
+		{3, 4},
+		{3, 3}, // 差は偶数人なので4人以上になる
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("top%d distance%d", tt.topK, tt.minDistance), func(t *testing.T) {
+			splits, err := DivideTopK(players, Options{TopK: tt.topK, MinDistance: tt.minDistance, Seed: 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(splits) != tt.topK {
+				t.Fatalf("splits = %d, want %d", len(splits), tt.topK)
+			}
+
+			want := max(tt.minDistance, 1)
+			for i, s := range splits {
+				if i > 0 && s.Score < splits[i-1].Score {
+					t.Errorf("split %d score %.1f is better than split %d score %.1f", i, s.Score, i-1, splits[i-1].Score)
+				}
+				if d := teamDistance(splits[0], s); s.Distance != d {
+					t.Errorf("split %d Distance = %d, want %d", i, s.Distance, d)
+				}
+				for j := 0; j < i; j++ {
+					if d := teamDistance(splits[j], s); d < want {
+						t.Errorf("splits %d and %d differ by %d players, want at least %d", j, i, d, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDivideTopKSkipsMirrors(t *testing.T) {
+	// 全員同じレーティングなら全ての組み合わせが同じ評価値になる
+	players := testRoster(10)
+	for i := range players {
+		players[i].Rating = 1500
+	}
+
+	splits, err := DivideTopK(players, Options{TopK: 300, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10C5 = 252通りのうち、チームを入れ替えただけのものを除いた126通り
+	if len(splits) != 126 {
+		t.Errorf("splits = %d, want 126", len(splits))
+	}
+	seen := make(map[string]bool)
+	for _, s := range splits {
+		key := splitKey(s)
+		if seen[key] {
+			t.Errorf("split %v appears twice", playerIDList(s.Team1.Players))
+		}
+		seen[key] = true
+	}
+}
@@ -0,0 +1,249 @@
+package balance
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ModeDraft はキャプテンドラフトで作ったチーム分けのモード名
+const ModeDraft = "draft"
+
+// draftPenaltyWeight は自動ピックでロールペナルティ1点をレーティングに換算する重み
+const draftPenaltyWeight = 100
+
+// DraftPick はドラフトの1ピック
+type DraftPick struct {
+	Number   int    `json:"number"`   // ピック番号（1始まり）
+	Team     int    `json:"team"`     // ピックしたチーム（1または2）
+	PlayerID string `json:"playerId"` // 指名されたプレイヤーID
+	Auto     bool   `json:"auto"`     // 自動ピックかどうか
+}
+
+// Draft はキャプテンによるスネークドラフトの状態
+// キャプテンを除く8人を 1, 2, 2, 1, 1, 2, 2, 1 の順（スネーク）でチームが指名する
+type Draft struct {
+	Players  []Player    `json:"players"`  // 参加者全員（キャプテンを含む）
+	Captains [2]string   `json:"captains"` // チーム1・チーム2のキャプテンのプレイヤーID
+	Team1    []string    `json:"team1"`    // チーム1のプレイヤーID（キャプテンが先頭）
+	Team2    []string    `json:"team2"`    // チーム2のプレイヤーID（キャプテンが先頭）
+	Picks    []DraftPick `json:"picks"`    // ピック履歴
+}
+
+// NewDraft は10人のプレイヤーでドラフトを開始する
+// captains: キャプテンのプレイヤーID（[チーム1, チーム2]）。空の場合はレーティング上位2人が
+// キャプテンになり、先に指名できるチーム1は2番手のプレイヤーが担当する
+func NewDraft(players []Player, captains []string) (*Draft, error) {
+	if err := validateRoster(players); err != nil {
+		return nil, err
+	}
+
+	d := &Draft{Players: players}
+
+	switch len(captains) {
+	case 0:
+		order := make([]int, len(players))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return players[order[a]].Rating > players[order[b]].Rating
+		})
+		d.Captains = [2]string{players[order[1]].ID, players[order[0]].ID}
+	case 2:
+		if captains[0] == captains[1] {
+			return nil, fmt.Errorf("キャプテンに同じプレイヤーは指定できません: %s", captains[0])
+		}
+		for _, id := range captains {
+			if _, ok := d.player(id); !ok {
+				return nil, fmt.Errorf("キャプテン%sはロスターにいません", id)
+			}
+		}
+		d.Captains = [2]string{captains[0], captains[1]}
+	default:
+		return nil, fmt.Errorf("キャプテンは2人指定してください（現在%d人）", len(captains))
+	}
+
+	d.Team1 = []string{d.Captains[0]}
+	d.Team2 = []string{d.Captains[1]}
+
+	return d, nil
+}
+
+// Done はドラフトが終了したか判定する
+func (d *Draft) Done() bool {
+	return len(d.Team1) >= TeamSize && len(d.Team2) >= TeamSize
+}
+
+// Turn は次に指名するチーム（1または2）を返す。終了している場合は0
+func (d *Draft) Turn() int {
+	if d.Done() {
+		return 0
+	}
+	// スネーク順: 1, 2, 2, 1, 1, 2, 2, 1
+	if ((len(d.Picks)+1)/2)%2 == 0 {
+		return 1
+	}
+	return 2
+}
+
+// Available はまだ指名されていないプレイヤーを返す
+func (d *Draft) Available() []Player {
+	taken := make(map[string]bool, len(d.Team1)+len(d.Team2))
+	for _, id := range d.Team1 {
+		taken[id] = true
+	}
+	for _, id := range d.Team2 {
+		taken[id] = true
+	}
+
+	var available []Player
+	for _, p := range d.Players {
+		if !taken[p.ID] {
+			available = append(available, p)
+		}
+	}
+	return available
+}
+
+// Pick は現在の手番のチームがプレイヤーを指名する
+func (d *Draft) Pick(playerID string) error {
+	return d.pick(playerID, false)
+}
+
+// AutoPick は現在の手番のチームの代わりに自動で指名し、指名したプレイヤーIDを返す
+// チームに足りないロールを考慮し、レーティングからロールペナルティの増加分を引いた値が最大のプレイヤーを選ぶ
+func (d *Draft) AutoPick() (string, error) {
+	turn := d.Turn()
+	if turn == 0 {
+		return "", fmt.Errorf("ドラフトは終了しています")
+	}
+
+	members := d.teamPlayers(turn)
+	basePenalty := partialRolePenalty(members)
+
+	bestID := ""
+	bestValue := 0
+	for _, p := range d.Available() {
+		extra := partialRolePenalty(append(members, p)) - basePenalty
+		value := p.Rating - draftPenaltyWeight*extra
+		if bestID == "" || value > bestValue {
+			bestID = p.ID
+			bestValue = value
+		}
+	}
+
+	if err := d.pick(bestID, true); err != nil {
+		return "", err
+	}
+	return bestID, nil
+}
+
+// RunAuto は残りの指名を全て自動で行う
+func (d *Draft) RunAuto() error {
+	for !d.Done() {
+		if _, err := d.AutoPick(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Result は終了したドラフトのチームをロール配分・集計して返す
+func (d *Draft) Result() (*Split, error) {
+	if !d.Done() {
+		return nil, fmt.Errorf("ドラフトが終了していません（残り%d人）", len(d.Available()))
+	}
+
+	// チーム1を先頭5人としたビットマスクで自動チーム分けと同じ集計を行う
+	roster := append(d.teamPlayers(1), d.teamPlayers(2)...)
+	cand := candidate{mask: 1<<TeamSize - 1}
+	team1, team2 := splitPlayers(roster, cand.mask)
+	cand.obj.Base = float64(abs(maskRating(roster, cand.mask) - maskRating(roster, ^cand.mask&(1<<len(roster)-1))))
+	cand.obj.StdDevGap, cand.obj.TopGap = spreadObjectives(team1, team2)
+	cand.obj.UncertaintyGap = uncertaintyGap(team1, team2)
+	cand.score = cand.obj.Base
+
+	split := newSplit(roster, cand)
+	split.Mode = ModeDraft
+	split.GameMode = GameModeClassic
+	split.WinProbability = WinProbability(teamRatings(team1), teamRatings(team2), DefaultWinScale)
+	return split, nil
+}
+
+// pick は指名を記録する
+func (d *Draft) pick(playerID string, auto bool) error {
+	turn := d.Turn()
+	if turn == 0 {
+		return fmt.Errorf("ドラフトは終了しています")
+	}
+
+	available := false
+	for _, p := range d.Available() {
+		if p.ID == playerID {
+			available = true
+			break
+		}
+	}
+	if !available {
+		return fmt.Errorf("プレイヤー%sは指名できません", playerID)
+	}
+
+	if turn == 1 {
+		d.Team1 = append(d.Team1, playerID)
+	} else {
+		d.Team2 = append(d.Team2, playerID)
+	}
+	d.Picks = append(d.Picks, DraftPick{
+		Number:   len(d.Picks) + 1,
+		Team:     turn,
+		PlayerID: playerID,
+		Auto:     auto,
+	})
+	return nil
+}
+
+// player はプレイヤーIDからプレイヤーを探す
+func (d *Draft) player(id string) (Player, bool) {
+	for _, p := range d.Players {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Player{}, false
+}
+
+// teamPlayers は指定チームのプレイヤーを指名順に返す
+func (d *Draft) teamPlayers(team int) []Player {
+	ids := d.Team1
+	if team == 2 {
+		ids = d.Team2
+	}
+
+	players := make([]Player, 0, TeamSize)
+	for _, id := range ids {
+		if p, ok := d.player(id); ok {
+			players = append(players, p)
+		}
+	}
+	return players
+}
+
+// partialRolePenalty は5人未満のチームでも、ロールが重複しない最小のロールペナルティ合計を返す
+func partialRolePenalty(players []Player) int {
+	cost := make([][]int, len(Roles))
+	for i := range cost {
+		cost[i] = make([]int, len(Roles))
+		if i >= len(players) {
+			continue // 未定の枠はどのロールでもコスト0
+		}
+		for j, role := range Roles {
+			cost[i][j], _ = RolePenalty(players[i], role)
+		}
+	}
+
+	total := 0
+	for i, j := range solveAssignment(cost) {
+		total += cost[i][j]
+	}
+	return total
+}
@@ -0,0 +1,128 @@
+package balance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDraftSnakeOrder(t *testing.T) {
+	players := testRoster(10)
+	d, err := NewDraft(players, []string{"p0", "p1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var turns []int
+	for _, p := range players[2:] {
+		turns = append(turns, d.Turn())
+		if err := d.Pick(p.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if want := []int{1, 2, 2, 1, 1, 2, 2, 1}; !reflect.DeepEqual(turns, want) {
+		t.Errorf("pick order = %v, want %v", turns, want)
+	}
+	if !d.Done() || d.Turn() != 0 {
+		t.Errorf("draft not done after 8 picks")
+	}
+	if len(d.Team1) != TeamSize || len(d.Team2) != TeamSize {
+		t.Errorf("team sizes = %d, %d", len(d.Team1), len(d.Team2))
+	}
+	if err := d.Pick("p9"); err == nil {
+		t.Error("expected an error picking after the draft is done")
+	}
+
+	split, err := d.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if split.Mode != ModeDraft || split.Team1.Players[0].ID != "p0" || split.Team2.Players[0].ID != "p1" {
+		t.Errorf("result mode %s, captains %s/%s", split.Mode, split.Team1.Players[0].ID, split.Team2.Players[0].ID)
+	}
+}
+
+func TestNewDraftCaptains(t *testing.T) {
+	players := testRoster(10)
+	players[3].Rating = 3000
+	players[7].Rating = 2900
+
+	d, err := NewDraft(players, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 先に指名できるチーム1は2番手が担当する
+	if d.Captains != [2]string{"p7", "p3"} {
+		t.Errorf("captains = %v, want [p7 p3]", d.Captains)
+	}
+
+	tests := []struct {
+		name     string
+		captains []string
+	}{
+		{"same captain", []string{"p0", "p0"}},
+		{"unknown captain", []string{"p0", "p99"}},
+		{"one captain", []string{"p0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewDraft(players, tt.captains); err == nil {
+				t.Errorf("expected an error for captains %v", tt.captains)
+			}
+		})
+	}
+}
+
+func TestDraftAutoPick(t *testing.T) {
+	players := []Player{
+		{ID: "cap1", Rating: 1500, PreferredRoles: []string{"MID"}},
+		{ID: "cap2", Rating: 1500, PreferredRoles: []string{"MID"}},
+		{ID: "mid", Rating: 1800, PreferredRoles: []string{"MID"}}, // 最も高いがチーム1のMIDと重なる
+		{ID: "top", Rating: 1400, PreferredRoles: []string{"TOP"}}, // ロールが空いている中で最も高い
+		{ID: "jungle", Rating: 1300, PreferredRoles: []string{"JUNGLE"}},
+		{ID: "adc", Rating: 1200, PreferredRoles: []string{"ADC"}},
+		{ID: "support", Rating: 1100, PreferredRoles: []string{"SUPPORT"}},
+		{ID: "fill1", Rating: 1000, PreferredRoles: []string{FillRole}},
+		{ID: "fill2", Rating: 900, PreferredRoles: []string{FillRole}},
+		{ID: "fill3", Rating: 800, PreferredRoles: []string{FillRole}},
+	}
+
+	d, err := NewDraft(players, []string{"cap1", "cap2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := d.AutoPick()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "top" {
+		t.Errorf("auto pick = %s, want top (mid would double up on MID)", id)
+	}
+	if pick := d.Picks[0]; !pick.Auto || pick.Team != 1 || pick.PlayerID != "top" {
+		t.Errorf("first pick = %+v", pick)
+	}
+
+	if err := d.Pick("top"); err == nil {
+		t.Error("expected an error picking an already picked player")
+	}
+
+	if err := d.RunAuto(); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Done() {
+		t.Fatal("draft not done after RunAuto")
+	}
+	if _, err := d.AutoPick(); err == nil {
+		t.Error("expected an error auto picking after the draft is done")
+	}
+
+	split, err := d.Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2人目のMIDはどちらかのチームに入るしかないが、先に指名するチーム1は避けられる
+	if split.Team1.RolePenalty != 0 {
+		t.Errorf("auto draft left role penalty %d in team 1 %v", split.Team1.RolePenalty, playerIDList(split.Team1.Players))
+	}
+}
@@ -0,0 +1,119 @@
+package balance
+
+// LaneDiff は同じロール同士（対面）のレーティング差
+type LaneDiff struct {
+	Role          string `json:"role"`          // ロール
+	Team1PlayerID string `json:"team1PlayerId"` // チーム1の担当プレイヤー
+	Team1Rating   int    `json:"team1Rating"`   // チーム1の担当プレイヤーのロール別レーティング
+	Team2PlayerID string `json:"team2PlayerId"` // チーム2の担当プレイヤー
+	Team2Rating   int    `json:"team2Rating"`   // チーム2の担当プレイヤーのロール別レーティング
+	Diff          int    `json:"diff"`          // 差（チーム1 - チーム2）
+}
+
+// laneDiffs はロール配分済みの2チームから対面ごとの差を計算する
+func laneDiffs(team1, team2 Team) []LaneDiff {
+	if len(team1.Roles) != len(Roles) || len(team2.Roles) != len(Roles) {
+		return nil
+	}
+
+	diffs := make([]LaneDiff, len(Roles))
+	for i, role := range Roles {
+		a := team1.Roles[i]
+		b := team2.Roles[i]
+		diffs[i] = LaneDiff{
+			Role:          role,
+			Team1PlayerID: a.PlayerID,
+			Team1Rating:   a.Rating,
+			Team2PlayerID: b.PlayerID,
+			Team2Rating:   b.Rating,
+			Diff:          a.Rating - b.Rating,
+		}
+	}
+	return diffs
+}
+
+// rolePlan はチーム内の1通りのロール配分
+type rolePlan struct {
+	perm    []int // perm[i]: i番目のプレイヤーのロール
+	lanes   []int // ロールごとのレーティング
+	total   int   // ロール別レーティングの合計
+	penalty int   // ロールペナルティの合計
+}
+
+// rolePermutations はロールの全順列（5! = 120通り）
+var rolePermutations = permutations(len(Roles))
+
+// rolePlans はチームの全ロール配分を列挙する
+func rolePlans(team []Player) []rolePlan {
+	plans := make([]rolePlan, len(rolePermutations))
+	for k, perm := range rolePermutations {
+		plan := rolePlan{perm: perm, lanes: make([]int, len(Roles))}
+		for i, p := range team {
+			role := Roles[perm[i]]
+			rating := p.RoleRating(role)
+			penalty, _ := RolePenalty(p, role)
+			plan.lanes[perm[i]] = rating
+			plan.total += rating
+			plan.penalty += penalty
+		}
+		plans[k] = plan
+	}
+	return plans
+}
+
+// bestLanePlans は2チームのロール配分の組み合わせから
+// チーム合計差・対面差・ロールペナルティの重み付き和が最小になるものを返す
+func bestLanePlans(team1, team2 []Player, opts Options) (score float64, perm1, perm2 []int) {
+	plans1 := rolePlans(team1)
+	plans2 := rolePlans(team2)
+
+	best := -1.0
+	for i := range plans1 {
+		a := &plans1[i]
+		for j := range plans2 {
+			b := &plans2[j]
+
+			laneGap := 0
+			for k := range a.lanes {
+				laneGap += abs(a.lanes[k] - b.lanes[k])
+			}
+
+			s := float64(abs(a.total-b.total)) +
+				opts.LaneWeight*float64(laneGap) +
+				opts.RolePenaltyWeight*float64(a.penalty+b.penalty)
+
+			if best < 0 || s < best {
+				best = s
+				perm1 = a.perm
+				perm2 = b.perm
+			}
+		}
+	}
+
+	return best, perm1, perm2
+}
+
+// permutations は0..n-1の全順列を返す
+func permutations(n int) [][]int {
+	var result [][]int
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+
+	var generate func(k int)
+	generate = func(k int) {
+		if k == n {
+			result = append(result, append([]int(nil), perm...))
+			return
+		}
+		for i := k; i < n; i++ {
+			perm[k], perm[i] = perm[i], perm[k]
+			generate(k + 1)
+			perm[k], perm[i] = perm[i], perm[k]
+		}
+	}
+	generate(0)
+
+	return result
+}
@@ -0,0 +1,119 @@
+package balance
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLaneDiffs(t *testing.T) {
+	team := func(prefix string, ratings ...int) Team {
+		var roles []RoleAssignment
+		for i, r := range ratings {
+			roles = append(roles, RoleAssignment{PlayerID: fmt.Sprintf("%s%d", prefix, i), Role: Roles[i], Rating: r})
+		}
+		return Team{Roles: roles}
+	}
+
+	tests := []struct {
+		name  string
+		team1 Team
+		team2 Team
+		want  []int // ロール順の差（nilは計算しない）
+	}{
+		{"even lanes", team("a", 1000, 1200, 1400, 1600, 1800), team("b", 1000, 1200, 1400, 1600, 1800), []int{0, 0, 0, 0, 0}},
+		{"mixed lanes", team("a", 1500, 1000, 1400, 1600, 900), team("b", 1000, 1300, 1400, 1700, 1200), []int{500, -300, 0, -100, -300}},
+		{"roles not assigned", Team{}, team("b", 1000, 1000, 1000, 1000, 1000), nil},
+		{"partial roles", team("a", 1000, 1000), team("b", 1000, 1000, 1000, 1000, 1000), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := laneDiffs(tt.team1, tt.team2)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("laneDiffs = %+v, want nil", got)
+				}
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("laneDiffs = %d lanes, want %d", len(got), len(tt.want))
+			}
+			for i, d := range got {
+				if d.Role != Roles[i] || d.Diff != tt.want[i] {
+					t.Errorf("lane %d = %s %d, want %s %d", i, d.Role, d.Diff, Roles[i], tt.want[i])
+				}
+				if d.Team1PlayerID != tt.team1.Roles[i].PlayerID || d.Team2PlayerID != tt.team2.Roles[i].PlayerID {
+					t.Errorf("lane %s players = %s vs %s", d.Role, d.Team1PlayerID, d.Team2PlayerID)
+				}
+			}
+		})
+	}
+}
+
+func TestRolePlansCoverEveryPermutation(t *testing.T) {
+	team := []Player{
+		{ID: "a", Rating: 1000, PreferredRoles: []string{"TOP"}, RoleRatings: map[string]int{"TOP": 1500}},
+		{ID: "b", Rating: 1000, PreferredRoles: []string{"JUNGLE"}},
+		{ID: "c", Rating: 1000, PreferredRoles: []string{"MID"}},
+		{ID: "d", Rating: 1000, PreferredRoles: []string{"ADC"}},
+		{ID: "e", Rating: 1000, PreferredRoles: []string{"SUPPORT"}},
+	}
+
+	plans := rolePlans(team)
+	if len(plans) != 120 {
+		t.Fatalf("plans = %d, want 120", len(plans))
+	}
+	for _, plan := range plans {
+		want := 5000
+		if plan.perm[0] == 0 {
+			want += 500 // aがTOPのときだけロール別レーティング
+		}
+		if plan.total != want {
+			t.Errorf("plan %v total = %d, want %d", plan.perm, plan.total, want)
+		}
+		onRole := 0
+		for i, role := range plan.perm {
+			if role == i {
+				onRole++
+			}
+		}
+		if onRole == len(team) && plan.penalty != 0 {
+			t.Errorf("plan %v has penalty %d with everyone on their role", plan.perm, plan.penalty)
+		}
+		if onRole < len(team) && plan.penalty == 0 {
+			t.Errorf("plan %v has no penalty with %d off-role players", plan.perm, len(team)-onRole)
+		}
+	}
+}
+
+func TestLaneModeMatchesSpecialists(t *testing.T) {
+	// ロールごとに同じ強さのスペシャリストが2人ずついる
+	laneRatings := map[string]int{"TOP": 1600, "JUNGLE": 2000, "MID": 1800, "ADC": 1400, "SUPPORT": 1200}
+	var players []Player
+	for _, role := range Roles {
+		for k := 0; k < 2; k++ {
+			players = append(players, Player{
+				ID:             fmt.Sprintf("%s%d", role, k),
+				Rating:         1000,
+				PreferredRoles: []string{role},
+				RoleRatings:    map[string]int{role: laneRatings[role]},
+			})
+		}
+	}
+
+	for seed := int64(1); seed <= 5; seed++ {
+		split, err := Divide(players, Options{Mode: ModeLane, Seed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if split.LaneGap != 0 || split.RoleDiff != 0 {
+			t.Errorf("seed %d: LaneGap = %d, RoleDiff = %d, want 0", seed, split.LaneGap, split.RoleDiff)
+		}
+		for _, d := range split.LaneDiffs {
+			if d.Team1Rating != laneRatings[d.Role] || d.Team2Rating != laneRatings[d.Role] {
+				t.Errorf("seed %d: %s lane %s (%d) vs %s (%d), want both specialists",
+					seed, d.Role, d.Team1PlayerID, d.Team1Rating, d.Team2PlayerID, d.Team2Rating)
+			}
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package balance
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// ロビー分けの探索回数と時間
+// 探索は回数で打ち切るので、同じ入力とシードからは同じロビー分けになる。時間は負荷が高い場合の安全のための上限
+const (
+	defaultLobbyIterations = 5000
+	maxLobbyIterations     = 100000 // 30人程度で最大の探索時間に収まる回数
+	defaultLobbyTimeBudget = 2 * time.Second
+	maxLobbyTimeBudget     = 5 * time.Second
+)
+
+// LobbyOptions は複数ロビーへの振り分けのオプション
+type LobbyOptions struct {
+	// Options は各ロビー内のチーム分けのオプション（制約・前回チーム・TopKは使用できない）
+	Options
+	// Bench はベンチに回すプレイヤーID。人数が足りない分はロスターの末尾から補う
+	Bench []string `json:"bench,omitempty"`
+	// TimeBudgetMs は探索時間の安全のための上限（ミリ秒、デフォルト: 2000、最大: 5000）
+	TimeBudgetMs int `json:"timeBudgetMs,omitempty"`
+	// Iterations は試す入れ替え回数（デフォルト: 5000、最大: 100000）。
+	// 時間の上限で打ち切られた場合（結果のTimedOut）は、結果のSeedとIterationsを指定すると同じロビー分けを再現できる
+	Iterations int `json:"iterations,omitempty"`
+	// SpreadWeight はロビー間のレーティング合計の差（最大 - 最小）の重み（デフォルト: 1.0）
+	SpreadWeight float64 `json:"spreadWeight,omitempty"`
+}
+
+// Lobby は1ロビー分のチーム分け結果
+type Lobby struct {
+	Number        int     `json:"number"`        // ロビー番号（1始まり）
+	AverageRating float64 `json:"averageRating"` // ロビー全体の平均レーティング
+	*Split
+}
+
+// LobbyResult は複数ロビーへの振り分け結果
+type LobbyResult struct {
+	Lobbies    []Lobby  `json:"lobbies"`    // ロビーごとのチーム分け
+	Bench      []Player `json:"bench"`      // ベンチのプレイヤー
+	Spread     float64  `json:"spread"`     // ロビー平均レーティングの最大と最小の差
+	Score      float64  `json:"score"`      // 探索の評価値（小さいほど良い）
+	Iterations int      `json:"iterations"` // 探索した入れ替え回数
+	TimedOut   bool     `json:"timedOut"`   // 時間の上限で探索を打ち切ったか（再現にはIterationsの指定が必要）
+	Seed       int64    `json:"seed"`       // 使用した乱数のシード
+}
+
+// DivideLobbies はプレイヤーを10人ずつのロビーに振り分け、各ロビーを2チームに分ける
+// 10で割り切れない人数はベンチに回す。各ロビー内のチーム差とロビー間の平均差の重み付き和を
+// 局所探索（ロビー間のプレイヤー入れ替え）で指定の回数だけ改善する
+func DivideLobbies(players []Player, opts LobbyOptions) (*LobbyResult, error) {
+	lobbySize := TeamSize * 2
+	if len(players) < lobbySize {
+		return nil, fmt.Errorf("プレイヤーは%d人以上必要です（現在%d人）", lobbySize, len(players))
+	}
+	if opts.hasConstraints() || len(opts.PreviousTeam1) > 0 {
+		return nil, fmt.Errorf("ロビー分けでは制約と前回チームを指定できません")
+	}
+
+	seen := make(map[string]bool, len(players))
+	for _, p := range players {
+		if p.ID == "" {
+			return nil, fmt.Errorf("プレイヤーIDが空です: %s", p.Name)
+		}
+		if seen[p.ID] {
+			return nil, fmt.Errorf("プレイヤーIDが重複しています: %s", p.ID)
+		}
+		seen[p.ID] = true
+	}
+
+	active, bench, err := pickBench(players, opts.Bench, len(players)%lobbySize)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.GameMode == GameModeARAM {
+		active = withAramRatings(active)
+	}
+
+	budget := time.Duration(opts.TimeBudgetMs) * time.Millisecond
+	if budget <= 0 {
+		budget = defaultLobbyTimeBudget
+	} else if budget > maxLobbyTimeBudget {
+		budget = maxLobbyTimeBudget
+	}
+	spreadWeight := opts.SpreadWeight
+	if spreadWeight <= 0 {
+		spreadWeight = 1.0
+	}
+
+	iterations := opts.Iterations
+	if iterations <= 0 {
+		iterations = defaultLobbyIterations
+	} else if iterations > maxLobbyIterations {
+		iterations = maxLobbyIterations
+	}
+	seed := opts.Seed
+	if seed == 0 {
+		seed = newSeed()
+	}
+
+	search := newLobbySearch(active, len(active)/lobbySize, spreadWeight)
+	search.run(newRand(seed), time.Now().Add(budget), iterations)
+
+	// 確定したロビーごとに指定のオプションでチーム分け
+	divideOpts := opts.Options
+	divideOpts.TopK = 1
+	divideOpts.Seed = seed
+
+	result := &LobbyResult{
+		Bench:      bench,
+		Score:      search.bestScore,
+		Iterations: search.iterations,
+		TimedOut:   search.timedOut,
+		Seed:       seed,
+	}
+
+	minAvg, maxAvg := 0.0, 0.0
+	for i, members := range search.best {
+		roster := make([]Player, len(members))
+		for k, idx := range members {
+			roster[k] = active[idx]
+		}
+
+		split, err := Divide(roster, divideOpts)
+		if err != nil {
+			return nil, fmt.Errorf("ロビー%dのチーム分けに失敗: %w", i+1, err)
+		}
+
+		avg := float64(split.Team1.TotalRating+split.Team2.TotalRating) / float64(lobbySize)
+		if i == 0 || avg < minAvg {
+			minAvg = avg
+		}
+		if i == 0 || avg > maxAvg {
+			maxAvg = avg
+		}
+
+		result.Lobbies = append(result.Lobbies, Lobby{
+			Number:        i + 1,
+			AverageRating: avg,
+			Split:         split,
+		})
+	}
+	result.Spread = maxAvg - minAvg
+
+	return result, nil
+}
+
+// pickBench はベンチに回すプレイヤーを決め、残りのプレイヤーと分けて返す
+// requested: ベンチ希望のプレイヤーID（先頭から優先）
+// count: ベンチに回す人数
+func pickBench(players []Player, requested []string, count int) (active, bench []Player, err error) {
+	benched := make(map[string]bool, count)
+	for _, id := range requested {
+		if len(benched) >= count {
+			break
+		}
+		found := false
+		for _, p := range players {
+			if p.ID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("ベンチ指定のプレイヤー%sはロスターにいません", id)
+		}
+		benched[id] = true
+	}
+
+	// 足りない分はロスターの末尾（後から参加したプレイヤー）から補う
+	for i := len(players) - 1; i >= 0 && len(benched) < count; i-- {
+		benched[players[i].ID] = true
+	}
+
+	for _, p := range players {
+		if benched[p.ID] {
+			bench = append(bench, p)
+		} else {
+			active = append(active, p)
+		}
+	}
+	return active, bench, nil
+}
+
+// lobbySearch はロビー間のプレイヤー入れ替えによる局所探索の状態
+type lobbySearch struct {
+	players      []Player
+	spreadWeight float64
+
+	lobbies [][]int   // ロビーごとのプレイヤー番号
+	costs   []float64 // ロビーごとのチーム差（最良の分け方）
+	totals  []int     // ロビーごとのレーティング合計
+
+	best       [][]int
+	bestScore  float64
+	iterations int
+	timedOut   bool // 回数に達する前に期限を過ぎた
+}
+
+// newLobbySearch はレーティング順のスネーク配置で初期解を作る
+func newLobbySearch(players []Player, lobbyCount int, spreadWeight float64) *lobbySearch {
+	order := make([]int, len(players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return players[order[a]].Rating > players[order[b]].Rating
+	})
+
+	s := &lobbySearch{
+		players:      players,
+		spreadWeight: spreadWeight,
+		lobbies:      make([][]int, lobbyCount),
+		costs:        make([]float64, lobbyCount),
+		totals:       make([]int, lobbyCount),
+	}
+
+	for k, idx := range order {
+		round, pos := k/lobbyCount, k%lobbyCount
+		if round%2 == 1 {
+			pos = lobbyCount - 1 - pos
+		}
+		s.lobbies[pos] = append(s.lobbies[pos], idx)
+	}
+
+	for i := range s.lobbies {
+		s.refresh(i)
+	}
+	s.bestScore = s.score()
+	s.best = s.snapshot()
+
+	return s
+}
+
+// run はlimit回まで2ロビー間のランダムな入れ替えを試し、悪化しない入れ替えを採用する
+// 期限を過ぎた場合はlimit回に達していなくても打ち切る
+func (s *lobbySearch) run(rng *rand.Rand, deadline time.Time, limit int) {
+	if len(s.lobbies) < 2 {
+		return
+	}
+
+	current := s.bestScore
+	for s.bestScore > 0 {
+		if s.iterations >= limit {
+			break
+		}
+		if !time.Now().Before(deadline) {
+			s.timedOut = true
+			break
+		}
+		s.iterations++
+
+		a := rng.Intn(len(s.lobbies))
+		b := rng.Intn(len(s.lobbies) - 1)
+		if b >= a {
+			b++
+		}
+		i := rng.Intn(len(s.lobbies[a]))
+		j := rng.Intn(len(s.lobbies[b]))
+
+		s.lobbies[a][i], s.lobbies[b][j] = s.lobbies[b][j], s.lobbies[a][i]
+		s.refresh(a)
+		s.refresh(b)
+
+		next := s.score()
+		if next <= current {
+			current = next
+			if next < s.bestScore {
+				s.bestScore = next
+				s.best = s.snapshot()
+			}
+			continue
+		}
+
+		// 悪化した場合は元に戻す
+		s.lobbies[a][i], s.lobbies[b][j] = s.lobbies[b][j], s.lobbies[a][i]
+		s.refresh(a)
+		s.refresh(b)
+	}
+}
+
+// refresh はロビーiのレーティング合計と最良のチーム差を再計算する
+func (s *lobbySearch) refresh(i int) {
+	members := s.lobbies[i]
+	ratings := make([]int, len(members))
+	total := 0
+	for k, idx := range members {
+		ratings[k] = s.players[idx].Rating
+		total += ratings[k]
+	}
+	s.totals[i] = total
+
+	best := -1
+	for mask := 0; mask < 1<<len(ratings); mask++ {
+		// チームを入れ替えただけの組み合わせは除く（先頭のプレイヤーは常にチーム1）
+		if mask&1 == 0 || bits.OnesCount(uint(mask)) != TeamSize {
+			continue
+		}
+		sum := 0
+		for k, r := range ratings {
+			if mask&(1<<k) != 0 {
+				sum += r
+			}
+		}
+		diff := abs(2*sum - total)
+		if best < 0 || diff < best {
+			best = diff
+		}
+	}
+	s.costs[i] = float64(best)
+}
+
+// score はロビー内チーム差の合計とロビー間のレーティング合計差の重み付き和を返す
+func (s *lobbySearch) score() float64 {
+	total := 0.0
+	minTotal, maxTotal := s.totals[0], s.totals[0]
+	for i := range s.lobbies {
+		total += s.costs[i]
+		minTotal = min(minTotal, s.totals[i])
+		maxTotal = max(maxTotal, s.totals[i])
+	}
+	return total + s.spreadWeight*float64(maxTotal-minTotal)
+}
+
+// snapshot は現在のロビー構成のコピーを返す
+func (s *lobbySearch) snapshot() [][]int {
+	lobbies := make([][]int, len(s.lobbies))
+	for i, members := range s.lobbies {
+		lobbies[i] = append([]int(nil), members...)
+	}
+	return lobbies
+}
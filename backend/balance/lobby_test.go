@@ -0,0 +1,50 @@
+package balance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLobbyIterationsRespectTimeBudget(t *testing.T) {
+	players := testRoster(30)
+	start := time.Now()
+	result, err := DivideLobbies(players, LobbyOptions{Iterations: maxLobbyIterations, TimeBudgetMs: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.TimedOut {
+		t.Errorf("TimedOut = false after %d of %d iterations", result.Iterations, maxLobbyIterations)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("DivideLobbies took %v with a 50ms budget (%d iterations)", elapsed, result.Iterations)
+	}
+}
+
+func TestLobbySeedReproducible(t *testing.T) {
+	players := testRoster(30)
+	for seed := int64(1); seed <= 5; seed++ {
+		a, err := DivideLobbies(players, LobbyOptions{Options: Options{Seed: seed}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := DivideLobbies(players, LobbyOptions{Options: Options{Seed: seed}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.TimedOut || b.TimedOut {
+			t.Fatalf("seed %d: default search hit the time budget", seed)
+		}
+		if a.Iterations != b.Iterations || lobbyKey(a) != lobbyKey(b) {
+			t.Errorf("seed %d: lobbies differ between runs (%d vs %d iterations)", seed, a.Iterations, b.Iterations)
+		}
+	}
+}
+
+// lobbyKey はロビーごとのチーム構成を比較用の文字列にする
+func lobbyKey(r *LobbyResult) string {
+	key := ""
+	for _, l := range r.Lobbies {
+		key += idKey(playerIDList(l.Team1.Players)) + "|" + idKey(playerIDList(l.Team2.Players)) + "/"
+	}
+	return key
+}
@@ -0,0 +1,74 @@
+package balance
+
+import (
+	"lol-team-backend/rating"
+	"math"
+)
+
+// TeamSize は1チームの人数
+const TeamSize = 5
+
+// Roles はサモナーズリフトのロール（表示順）
+var Roles = []string{"TOP", "JUNGLE", "MID", "ADC", "SUPPORT"}
+
+// Player はチーム分けの対象となるプレイヤー
+type Player struct {
+	ID             string         `json:"id"`                    // プレイヤーID（ロスター内で一意）
+	Name           string         `json:"name"`                  // 表示名（サモナー名#タグ等）
+	Rating         int            `json:"rating"`                // レーティング（rating.FromTierの値）
+	PreferredRoles []string       `json:"preferredRoles"`        // 希望ロール（第1希望から順に。"FILL"は残り全てのロール）
+	RoleRatings    map[string]int `json:"roleRatings,omitempty"` // ロール別MMR（GetRoleMMRの値）
+	AramRating     int            `json:"aramRating,omitempty"`  // ARAMのレーティング（GetARAMRatingの値）
+	Deviation      int            `json:"deviation,omitempty"`   // レーティングの不確かさ（GetRoleMMRのDeviation、0は不明）
+}
+
+// RoleRating はプレイヤーの指定ロールでのレーティングを返す（ロール別MMRがなければRating）
+func (p Player) RoleRating(role string) int {
+	if rating, ok := p.RoleRatings[role]; ok {
+		return rating
+	}
+	return p.Rating
+}
+
+// Team はチーム分け結果の片側チーム
+type Team struct {
+	Players       []Player         `json:"players"`       // 所属プレイヤー
+	TotalRating   int              `json:"totalRating"`   // レーティング合計
+	AverageRating float64          `json:"averageRating"` // 平均レーティング
+	AverageTier   rating.Rank      `json:"averageTier"`   // 平均レーティングのティア情報（rating.ToTier）
+	Roles         []RoleAssignment `json:"roles"`         // ロール配分（ロール順）
+	RolePenalty   int              `json:"rolePenalty"`   // ロール配分のペナルティ合計
+	RoleRating    int              `json:"roleRating"`    // 割り当てロールでのレーティング合計
+	StdDev        float64          `json:"stdDev"`        // レーティングの標準偏差
+	TopRating     int              `json:"topRating"`     // 最上位プレイヤーのレーティング
+}
+
+// newTeam はプレイヤー一覧からチームの集計値を計算する
+// roles: ロール配分（ロールなしのモードではnil）
+func newTeam(players []Player, roles []RoleAssignment) Team {
+	total := 0
+	for _, p := range players {
+		total += p.Rating
+	}
+
+	average := 0.0
+	if len(players) > 0 {
+		average = float64(total) / float64(len(players))
+	}
+
+	team := Team{
+		Players:       players,
+		TotalRating:   total,
+		AverageRating: average,
+		AverageTier:   rating.ToTier(int(math.Round(average))),
+	}
+	team.StdDev, team.TopRating = ratingSpread(players)
+
+	team.Roles = roles
+	for _, a := range roles {
+		team.RolePenalty += a.Penalty
+		team.RoleRating += a.Rating
+	}
+
+	return team
+}
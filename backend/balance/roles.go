@@ -0,0 +1,152 @@
+package balance
+
+import (
+	"fmt"
+	"math"
+)
+
+// FillRole は希望ロールに含めると、それ以降の未指定ロール全てを同じ順位で希望したものとみなす
+const FillRole = "FILL"
+
+// OffRolePenalty は希望ロール以外に配置された場合のペナルティ
+const OffRolePenalty = 10
+
+// rolePenaltyScale はロールペナルティをロール別MMRより優先させるための係数
+const rolePenaltyScale = 100000
+
+// RoleAssignment はプレイヤーへのロール割り当て
+type RoleAssignment struct {
+	PlayerID       string `json:"playerId"`       // プレイヤーID
+	Name           string `json:"name"`           // 表示名
+	Role           string `json:"role"`           // 割り当てられたロール
+	PreferenceRank int    `json:"preferenceRank"` // 希望順位（1=第1希望、0=希望外）
+	Penalty        int    `json:"penalty"`        // ロールペナルティ
+	Rating         int    `json:"rating"`         // 割り当てロールでのレーティング
+}
+
+// AssignRoles は5人のプレイヤーにロールを割り当てる
+// 希望順位によるペナルティの合計が最小になる割り当てを求め（割当問題）、
+// 同じペナルティの中ではロール別MMRの合計が最大になるものを選ぶ
+func AssignRoles(players []Player) ([]RoleAssignment, error) {
+	if len(players) != len(Roles) {
+		return nil, fmt.Errorf("ロール配分には%d人必要です（現在%d人）", len(Roles), len(players))
+	}
+
+	cost := make([][]int, len(players))
+	for i, p := range players {
+		cost[i] = make([]int, len(Roles))
+		for j, role := range Roles {
+			penalty, _ := RolePenalty(p, role)
+			cost[i][j] = penalty*rolePenaltyScale - p.RoleRating(role)
+		}
+	}
+
+	assignment := solveAssignment(cost)
+
+	return rolesFromPerm(players, assignment), nil
+}
+
+// rolesFromPerm はプレイヤーiにロールperm[i]を割り当てた結果をロール順に並べて返す
+func rolesFromPerm(players []Player, perm []int) []RoleAssignment {
+	result := make([]RoleAssignment, len(Roles))
+	for i, p := range players {
+		j := perm[i]
+		penalty, rank := RolePenalty(p, Roles[j])
+		result[j] = RoleAssignment{
+			PlayerID:       p.ID,
+			Name:           p.Name,
+			Role:           Roles[j],
+			PreferenceRank: rank,
+			Penalty:        penalty,
+			Rating:         p.RoleRating(Roles[j]),
+		}
+	}
+	return result
+}
+
+// RolePenalty はプレイヤーを指定ロールに配置した場合のペナルティと希望順位を返す
+// 第1希望は0、第2希望は1…と順位が下がるごとに1ずつ増え、希望外はOffRolePenalty（希望順位0）
+// 希望ロールが空の場合はどのロールでもペナルティ0
+func RolePenalty(p Player, role string) (penalty int, rank int) {
+	if len(p.PreferredRoles) == 0 {
+		return 0, 1
+	}
+
+	for i, preferred := range p.PreferredRoles {
+		if preferred == role || preferred == FillRole {
+			return i, i + 1
+		}
+	}
+
+	return OffRolePenalty, 0
+}
+
+// solveAssignment はn×nのコスト行列に対する最小コストの割り当てを返す（ハンガリアン法）
+// 戻り値のassignment[i]は行iに割り当てられた列
+func solveAssignment(cost [][]int) []int {
+	n := len(cost)
+
+	// 1始まりのポテンシャル法（O(n^3)）
+	u := make([]int, n+1)
+	v := make([]int, n+1)
+	p := make([]int, n+1) // p[j]: 列jに割り当てられた行
+	way := make([]int, n+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]int, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = math.MaxInt
+		}
+
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.MaxInt
+			j1 := 0
+
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	assignment := make([]int, n)
+	for j := 1; j <= n; j++ {
+		assignment[p[j]-1] = j - 1
+	}
+	return assignment
+}
@@ -0,0 +1,136 @@
+package balance
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// assignedRoles はプレイヤーIDごとの割り当てロールを返す
+func assignedRoles(t *testing.T, players []Player) map[string]RoleAssignment {
+	t.Helper()
+	roles, err := AssignRoles(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]RoleAssignment, len(roles))
+	for _, r := range roles {
+		byID[r.PlayerID] = r
+	}
+	return byID
+}
+
+func TestAssignRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		players []Player
+		want    map[string]string // プレイヤーIDごとの期待するロール
+		penalty int               // 期待するペナルティ合計
+	}{
+		{
+			// 先頭から第1希望を埋めるとaがTOPを取り、TOPしか希望しないbが希望外になる
+			name: "swap beats greedy first fit",
+			players: []Player{
+				{ID: "a", Rating: 1000, PreferredRoles: []string{"TOP", "MID"}},
+				{ID: "b", Rating: 1000, PreferredRoles: []string{"TOP"}},
+				{ID: "c", Rating: 1000, PreferredRoles: []string{"JUNGLE"}},
+				{ID: "d", Rating: 1000, PreferredRoles: []string{"ADC"}},
+				{ID: "e", Rating: 1000, PreferredRoles: []string{"SUPPORT"}},
+			},
+			want:    map[string]string{"a": "MID", "b": "TOP", "c": "JUNGLE", "d": "ADC", "e": "SUPPORT"},
+			penalty: 1,
+		},
+		{
+			name: "fill takes the remaining role",
+			players: []Player{
+				{ID: "a", Rating: 1000, PreferredRoles: []string{"MID", FillRole}},
+				{ID: "b", Rating: 1000, PreferredRoles: []string{"MID"}},
+				{ID: "c", Rating: 1000, PreferredRoles: []string{"JUNGLE"}},
+				{ID: "d", Rating: 1000, PreferredRoles: []string{"ADC"}},
+				{ID: "e", Rating: 1000, PreferredRoles: []string{"SUPPORT"}},
+			},
+			want:    map[string]string{"a": "TOP", "b": "MID", "c": "JUNGLE", "d": "ADC", "e": "SUPPORT"},
+			penalty: 1,
+		},
+		{
+			// 希望がない場合はペナルティ0で、ロール別MMRの合計が最大になる
+			name: "empty preferences follow role ratings",
+			players: []Player{
+				{ID: "a", Rating: 1000, RoleRatings: map[string]int{"SUPPORT": 1500}},
+				{ID: "b", Rating: 1000, RoleRatings: map[string]int{"MID": 1500}},
+				{ID: "c", Rating: 1000, RoleRatings: map[string]int{"TOP": 1500}},
+				{ID: "d", Rating: 1000, RoleRatings: map[string]int{"JUNGLE": 1500}},
+				{ID: "e", Rating: 1000, RoleRatings: map[string]int{"ADC": 1500}},
+			},
+			want:    map[string]string{"a": "SUPPORT", "b": "MID", "c": "TOP", "d": "JUNGLE", "e": "ADC"},
+			penalty: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := assignedRoles(t, tt.players)
+			penalty := 0
+			for id, role := range tt.want {
+				if got[id].Role != role {
+					t.Errorf("%s assigned %s, want %s", id, got[id].Role, role)
+				}
+				penalty += got[id].Penalty
+			}
+			if penalty != tt.penalty {
+				t.Errorf("total penalty = %d, want %d", penalty, tt.penalty)
+			}
+		})
+	}
+
+	if _, err := AssignRoles(testRoster(4)); err == nil {
+		t.Error("expected an error for 4 players")
+	}
+}
+
+func TestSolveAssignmentMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 200; trial++ {
+		n := 1 + rng.Intn(5)
+		cost := make([][]int, n)
+		for i := range cost {
+			cost[i] = make([]int, n)
+			for j := range cost[i] {
+				cost[i][j] = rng.Intn(21) - 10
+			}
+		}
+
+		total := func(assignment []int) int {
+			sum := 0
+			for i, j := range assignment {
+				sum += cost[i][j]
+			}
+			return sum
+		}
+
+		best := 0
+		first := true
+		var permute func(perm []int, k int)
+		permute = func(perm []int, k int) {
+			if k == len(perm) {
+				if sum := total(perm); first || sum < best {
+					best, first = sum, false
+				}
+				return
+			}
+			for i := k; i < len(perm); i++ {
+				perm[k], perm[i] = perm[i], perm[k]
+				permute(perm, k+1)
+				perm[k], perm[i] = perm[i], perm[k]
+			}
+		}
+		perm := make([]int, n)
+		for i := range perm {
+			perm[i] = i
+		}
+		permute(perm, 0)
+
+		if got := total(solveAssignment(cost)); got != best {
+			t.Fatalf("trial %d: cost %v solved to %d, want %d", trial, cost, got, best)
+		}
+	}
+}
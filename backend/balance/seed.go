@@ -0,0 +1,16 @@
+package balance
+
+import "math/rand"
+
+// maxSeed はシードの上限（JavaScriptの数値で誤差なく扱える範囲）
+const maxSeed = 1 << 53
+
+// newSeed は未指定の場合に使うランダムなシードを返す（1以上）
+func newSeed() int64 {
+	return rand.Int63n(maxSeed-1) + 1
+}
+
+// newRand はシードから乱数生成器を作る
+func newRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
@@ -0,0 +1,57 @@
+package balance
+
+import "math"
+
+// Objectives はチーム分けの各評価項目の値
+// Score = Base + StdDevWeight*StdDevGap + TopGapWeight*TopGap + VarietyWeight*Repeats
+// + UncertaintyWeight*UncertaintyGap
+type Objectives struct {
+	Base           float64 `json:"base"`           // モードごとの基本評価値（ratingならレーティング合計の差）
+	StdDevGap      float64 `json:"stdDevGap"`      // チーム内レーティング標準偏差の差
+	TopGap         int     `json:"topGap"`         // 各チーム最上位プレイヤーのレーティング差
+	UncertaintyGap float64 `json:"uncertaintyGap"` // 各チームのレーティング合計の不確かさの差
+}
+
+// ratingSpread はチーム内のレーティングの標準偏差と最大値を返す
+func ratingSpread(players []Player) (stdDev float64, top int) {
+	if len(players) == 0 {
+		return 0, 0
+	}
+
+	mean := 0.0
+	top = players[0].Rating
+	for _, p := range players {
+		mean += float64(p.Rating)
+		top = max(top, p.Rating)
+	}
+	mean /= float64(len(players))
+
+	variance := 0.0
+	for _, p := range players {
+		d := float64(p.Rating) - mean
+		variance += d * d
+	}
+	return math.Sqrt(variance / float64(len(players))), top
+}
+
+// teamUncertainty はチームのレーティング合計の不確かさ（各プレイヤーのDeviationの二乗和の平方根）を返す
+func teamUncertainty(players []Player) float64 {
+	variance := 0.0
+	for _, p := range players {
+		d := float64(p.Deviation)
+		variance += d * d
+	}
+	return math.Sqrt(variance)
+}
+
+// uncertaintyGap は2チームのレーティング合計の不確かさの差を返す
+func uncertaintyGap(team1, team2 []Player) float64 {
+	return math.Abs(teamUncertainty(team1) - teamUncertainty(team2))
+}
+
+// spreadObjectives は2チームのレーティングの散らばり方の差を返す
+func spreadObjectives(team1, team2 []Player) (stdDevGap float64, topGap int) {
+	sd1, top1 := ratingSpread(team1)
+	sd2, top2 := ratingSpread(team2)
+	return math.Abs(sd1 - sd2), abs(top1 - top2)
+}
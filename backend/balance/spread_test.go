@@ -0,0 +1,136 @@
+package balance
+
+import (
+	"math"
+	"testing"
+)
+
+// ratedPlayers は指定したレーティングのプレイヤーを返す（IDはtestRosterと同じ）
+func ratedPlayers(ratings ...int) []Player {
+	players := testRoster(len(ratings))
+	for i, r := range ratings {
+		players[i].Rating = r
+	}
+	return players
+}
+
+func TestRatingSpread(t *testing.T) {
+	tests := []struct {
+		name    string
+		ratings []int
+		stdDev  float64
+		top     int
+	}{
+		{"no players", nil, 0, 0},
+		{"even team", []int{1500, 1500, 1500, 1500, 1500}, 0, 1500},
+		{"carry", []int{2500, 1250, 1250, 1250, 1250}, 500, 2500},
+		{"pairs", []int{1000, 2000, 1000, 2000}, 500, 2000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdDev, top := ratingSpread(ratedPlayers(tt.ratings...))
+			if math.Abs(stdDev-tt.stdDev) > 1e-9 || top != tt.top {
+				t.Errorf("ratingSpread = (%f, %d), want (%f, %d)", stdDev, top, tt.stdDev, tt.top)
+			}
+		})
+	}
+}
+
+func TestUncertaintyGap(t *testing.T) {
+	withDeviations := func(deviations ...int) []Player {
+		players := testRoster(len(deviations))
+		for i, d := range deviations {
+			players[i].Deviation = d
+		}
+		return players
+	}
+
+	tests := []struct {
+		name         string
+		team1, team2 []Player
+		want         float64
+	}{
+		{"unknown deviations", withDeviations(0, 0), withDeviations(0, 0), 0},
+		{"sum of squares", withDeviations(300, 400), withDeviations(500), 0},
+		{"one uncertain player", withDeviations(300, 0), withDeviations(0, 0), 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uncertaintyGap(tt.team1, tt.team2); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("uncertaintyGap = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpreadWeightsAvoidCarryTeams(t *testing.T) {
+	// 合計が同じになるのは「2100と900 / 1700と1300」を分ける組み合わせと、4人を同じチームにする組み合わせ
+	players := ratedPlayers(2100, 900, 1700, 1300, 1500, 1500, 1500, 1500, 1500, 1500)
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"std dev weight", Options{StdDevWeight: 1}},
+		{"top gap weight", Options{TopGapWeight: 0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 10; seed++ {
+				opts := tt.opts
+				opts.Seed = seed
+				split, err := Divide(players, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if split.Diff != 0 {
+					t.Fatalf("seed %d: Diff = %d, want 0", seed, split.Diff)
+				}
+
+				team1 := make(map[string]bool)
+				for _, p := range split.Team1.Players {
+					team1[p.ID] = true
+				}
+				if team1["p0"] == team1["p2"] {
+					t.Errorf("seed %d: 2100 and 1700 are on the same team", seed)
+				}
+
+				obj := split.Objectives
+				want := obj.Base + opts.StdDevWeight*obj.StdDevGap + opts.TopGapWeight*float64(obj.TopGap)
+				if math.Abs(split.Score-want) > 1e-9 {
+					t.Errorf("seed %d: Score = %f, want %f from %+v", seed, split.Score, want, obj)
+				}
+			}
+		})
+	}
+}
+
+func TestUncertaintyWeightSpreadsUncertainPlayers(t *testing.T) {
+	players := testRoster(10)
+	for i := range players {
+		players[i].Rating = 1500
+		if i < 4 {
+			players[i].Deviation = 300
+		}
+	}
+
+	for seed := int64(1); seed <= 10; seed++ {
+		split, err := Divide(players, Options{UncertaintyWeight: 1, Seed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		uncertain := 0
+		for _, p := range split.Team1.Players {
+			if p.Deviation > 0 {
+				uncertain++
+			}
+		}
+		if uncertain != 2 || split.Objectives.UncertaintyGap != 0 {
+			t.Errorf("seed %d: team1 has %d of 4 uncertain players (gap %.1f), want 2",
+				seed, uncertain, split.Objectives.UncertaintyGap)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package balance
+
+// TeammatePair は2人のプレイヤーが過去に同じチームになった回数
+type TeammatePair struct {
+	A     string `json:"a"`     // プレイヤーID
+	B     string `json:"b"`     // プレイヤーID
+	Count int    `json:"count"` // 同じチームになった回数
+}
+
+// teammateMatrix はプレイヤー番号の組ごとの同チーム回数
+type teammateMatrix [][]int
+
+// newTeammateMatrix は同チーム履歴をプレイヤー番号の行列に変換する（ロスター外のIDは無視）
+func newTeammateMatrix(players []Player, history []TeammatePair) teammateMatrix {
+	if len(history) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(players))
+	for i, p := range players {
+		index[p.ID] = i
+	}
+
+	m := make(teammateMatrix, len(players))
+	for i := range m {
+		m[i] = make([]int, len(players))
+	}
+	for _, pair := range history {
+		a, okA := index[pair.A]
+		b, okB := index[pair.B]
+		if !okA || !okB || a == b {
+			continue
+		}
+		m[a][b] += pair.Count
+		m[b][a] += pair.Count
+	}
+	return m
+}
+
+// repeats はビットマスクのチーム分けで、過去に同じチームだった組が再び同じチームになる回数の合計を返す
+func (m teammateMatrix) repeats(mask int) int {
+	if m == nil {
+		return 0
+	}
+
+	total := 0
+	for i := range m {
+		for j := i + 1; j < len(m); j++ {
+			if (mask>>i)&1 == (mask>>j)&1 {
+				total += m[i][j]
+			}
+		}
+	}
+	return total
+}
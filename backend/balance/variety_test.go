@@ -0,0 +1,71 @@
+package balance
+
+import "testing"
+
+func TestTeammateRepeats(t *testing.T) {
+	players := testRoster(10)
+	// p0〜p4がチーム1
+	const mask = 0b0000011111
+
+	tests := []struct {
+		name    string
+		history []TeammatePair
+		want    int
+	}{
+		{"no history", nil, 0},
+		{"same team", []TeammatePair{{A: "p0", B: "p1", Count: 3}}, 3},
+		{"other team", []TeammatePair{{A: "p5", B: "p9", Count: 2}}, 2},
+		{"opposite teams", []TeammatePair{{A: "p0", B: "p5", Count: 4}}, 0},
+		{"pairs add up", []TeammatePair{{A: "p0", B: "p1", Count: 1}, {A: "p1", B: "p0", Count: 2}, {A: "p6", B: "p7", Count: 1}}, 4},
+		{"unknown and self pairs are ignored", []TeammatePair{{A: "p0", B: "x", Count: 5}, {A: "p2", B: "p2", Count: 5}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTeammateMatrix(players, tt.history).repeats(mask); got != tt.want {
+				t.Errorf("repeats = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVarietyWeightSplitsFrequentTeammates(t *testing.T) {
+	// 全員同じレーティングなら、同チーム回数だけで組み合わせが決まる
+	players := testRoster(10)
+	for i := range players {
+		players[i].Rating = 1500
+	}
+	history := []TeammatePair{
+		{A: "p0", B: "p1", Count: 5},
+		{A: "p2", B: "p3", Count: 5},
+		{A: "p4", B: "p5", Count: 5},
+	}
+
+	for seed := int64(1); seed <= 10; seed++ {
+		split, err := Divide(players, Options{TeammateHistory: history, VarietyWeight: 100, Seed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if split.Repeats != 0 {
+			t.Errorf("seed %d: Repeats = %d, want 0", seed, split.Repeats)
+		}
+		team1 := make(map[string]bool)
+		for _, p := range split.Team1.Players {
+			team1[p.ID] = true
+		}
+		for _, pair := range history {
+			if team1[pair.A] == team1[pair.B] {
+				t.Errorf("seed %d: %s and %s are on the same team again", seed, pair.A, pair.B)
+			}
+		}
+	}
+
+	// 重みが0なら履歴は評価値に影響しない
+	split, err := Divide(players, Options{TeammateHistory: history, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if split.Score != 0 {
+		t.Errorf("score without variety weight = %.1f, want 0", split.Score)
+	}
+}
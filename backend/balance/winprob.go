@@ -0,0 +1,59 @@
+package balance
+
+import "math"
+
+// DefaultWinScale は勝率予測のデフォルトのスケール
+// レーティング差がこの値のとき、強い側の予測勝率は約91%（10:1）になる
+const DefaultWinScale = 1000.0
+
+// WinPrediction は両チームの予測勝率
+type WinPrediction struct {
+	Team1 float64 `json:"team1"` // チーム1の予測勝率（0-1）
+	Team2 float64 `json:"team2"` // チーム2の予測勝率（0-1）
+}
+
+// TeamStrength はチームの実効レーティングを返す
+// 各プレイヤーのレーティングをElo式の強さ 10^(r/scale) に変換して平均し、レーティングに戻す。
+// 単純平均と違い、高レーティングのプレイヤーほどチームへの影響が大きくなる
+func TeamStrength(ratings []int, scale float64) float64 {
+	if len(ratings) == 0 {
+		return 0
+	}
+	if scale <= 0 {
+		scale = DefaultWinScale
+	}
+
+	// オーバーフローを避けるため最大値を基準にする
+	top := float64(ratings[0])
+	for _, r := range ratings {
+		top = math.Max(top, float64(r))
+	}
+
+	sum := 0.0
+	for _, r := range ratings {
+		sum += math.Pow(10, (float64(r)-top)/scale)
+	}
+	return top + scale*math.Log10(sum/float64(len(ratings)))
+}
+
+// WinProbability は2チームのレーティングからロジスティックモデルで予測勝率を返す
+// scale: レーティング差のスケール（0以下の場合はDefaultWinScale）
+func WinProbability(team1, team2 []int, scale float64) WinPrediction {
+	if scale <= 0 {
+		scale = DefaultWinScale
+	}
+
+	diff := TeamStrength(team2, scale) - TeamStrength(team1, scale)
+	p := 1 / (1 + math.Pow(10, diff/scale))
+
+	return WinPrediction{Team1: p, Team2: 1 - p}
+}
+
+// teamRatings はプレイヤーのレーティング一覧を返す
+func teamRatings(players []Player) []int {
+	ratings := make([]int, len(players))
+	for i, p := range players {
+		ratings[i] = p.Rating
+	}
+	return ratings
+}
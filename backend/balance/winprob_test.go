@@ -0,0 +1,62 @@
+package balance
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWinProbability(t *testing.T) {
+	tests := []struct {
+		name         string
+		team1, team2 []int
+		scale        float64
+		want         float64 // チーム1の予測勝率（負の値は0.5より大きいことだけを確認）
+	}{
+		{"equal teams", []int{1500, 1500, 1500, 1500, 1500}, []int{1500, 1500, 1500, 1500, 1500}, 0, 0.5},
+		{"same strength in a different order", []int{1000, 1200, 1400, 1600, 1800}, []int{1800, 1600, 1400, 1200, 1000}, 0, 0.5},
+		{"one scale apart", []int{2500}, []int{1500}, 0, 10.0 / 11},
+		{"custom scale", []int{2000}, []int{1500}, 500, 10.0 / 11},
+		{"stronger team1", []int{1600, 1500, 1500, 1500, 1500}, []int{1500, 1500, 1500, 1500, 1500}, 0, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WinProbability(tt.team1, tt.team2, tt.scale)
+			if math.Abs(got.Team1+got.Team2-1) > 1e-9 {
+				t.Errorf("probabilities sum to %f", got.Team1+got.Team2)
+			}
+			if tt.want < 0 {
+				if got.Team1 <= 0.5 {
+					t.Errorf("Team1 = %f, want above 0.5", got.Team1)
+				}
+			} else if math.Abs(got.Team1-tt.want) > 1e-9 {
+				t.Errorf("Team1 = %f, want %f", got.Team1, tt.want)
+			}
+
+			// チームを入れ替えると予測勝率も入れ替わる
+			swapped := WinProbability(tt.team2, tt.team1, tt.scale)
+			if math.Abs(swapped.Team1-got.Team2) > 1e-9 || math.Abs(swapped.Team2-got.Team1) > 1e-9 {
+				t.Errorf("swapped = %+v, want the mirror of %+v", swapped, got)
+			}
+		})
+	}
+}
+
+func TestTeamStrength(t *testing.T) {
+	if got := TeamStrength([]int{1500, 1500, 1500}, 0); math.Abs(got-1500) > 1e-9 {
+		t.Errorf("TeamStrength of equal ratings = %f, want 1500", got)
+	}
+	if got := TeamStrength(nil, 0); got != 0 {
+		t.Errorf("TeamStrength of no players = %f, want 0", got)
+	}
+
+	// 平均が同じでも、強いプレイヤーがいるチームほど実効レーティングが高い
+	even := TeamStrength([]int{1500, 1500, 1500, 1500, 1500}, 0)
+	carried := TeamStrength([]int{2700, 1200, 1200, 1200, 1200}, 0)
+	if carried <= even {
+		t.Errorf("carried team strength %f, want above the even team %f", carried, even)
+	}
+	if carried >= 2700 {
+		t.Errorf("carried team strength %f, want below its best player", carried)
+	}
+}
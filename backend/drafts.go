@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"lol-team-backend/balance"
+	"lol-team-backend/session"
+	"net/http"
+)
+
+type DraftStartRequest struct {
+	Players  []balance.Player `json:"players"`
+	Captains []string         `json:"captains"` // [チーム1, チーム2]。省略時はレーティング上位2人
+	Auto     bool             `json:"auto"`     // trueの場合は全ての指名を自動で行う
+}
+
+type DraftPickRequest struct {
+	DraftID  string `json:"draftId"`
+	PlayerID string `json:"playerId"` // 空の場合は自動で指名する
+}
+
+type DraftResponse struct {
+	DraftID   string           `json:"draftId,omitempty"`
+	Draft     *balance.Draft   `json:"draft"`
+	Turn      int              `json:"turn"`      // 次に指名するチーム（終了時は0）
+	Available []balance.Player `json:"available"` // 指名可能なプレイヤー
+	Result    *balance.Split   `json:"result"`    // 終了時のチーム分け結果
+}
+
+var draftStore = session.NewDraftStore()
+
+func startDraftHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DraftStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Received draft start request - Players: %d, Auto: %v\n", len(req.Players), req.Auto)
+
+	draft, err := balance.NewDraft(req.Players, req.Captains)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to start draft: %v\n", err)
+		http.Error(w, fmt.Sprintf("Failed to start draft: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// 自動ドラフトは保存せずに結果だけを返す
+	if req.Auto {
+		if err := draft.RunAuto(); err != nil {
+			fmt.Printf("ERROR: Failed to run auto draft: %v\n", err)
+			http.Error(w, fmt.Sprintf("Failed to run auto draft: %v", err), http.StatusInternalServerError)
+			return
+		}
+		writeDraftResponse(w, "", draft)
+		return
+	}
+
+	id, err := draftStore.Create(draft)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to save draft: %v\n", err)
+		http.Error(w, fmt.Sprintf("Failed to save draft: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Printf("INFO: Draft started - ID: %s, Captains: %v\n", id, draft.Captains)
+	writeDraftResponse(w, id, draft)
+}
+
+func pickDraftHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req DraftPickRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	draft, err := draftStore.Update(req.DraftID, func(d *balance.Draft) error {
+		if req.PlayerID == "" {
+			_, err := d.AutoPick()
+			return err
+		}
+		return d.Pick(req.PlayerID)
+	})
+	if err != nil {
+		fmt.Printf("ERROR: Failed to pick in draft: %v\n", err)
+		http.Error(w, fmt.Sprintf("Failed to pick: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	last := draft.Picks[len(draft.Picks)-1]
+	fmt.Printf("INFO: Draft pick - ID: %s, Pick: %d, Team: %d, Player: %s\n",
+		req.DraftID, last.Number, last.Team, last.PlayerID)
+
+	writeDraftResponse(w, req.DraftID, draft)
+}
+
+func writeDraftResponse(w http.ResponseWriter, id string, draft *balance.Draft) {
+	resp := DraftResponse{
+		DraftID:   id,
+		Draft:     draft,
+		Turn:      draft.Turn(),
+		Available: draft.Available(),
+	}
+	if draft.Done() {
+		resp.Result, _ = draft.Result()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -2,4 +2,4 @@ module lol-team-backend
 
 go 1.25.0
 
-require github.com/joho/godotenv v1.5.1 // indirect
+require github.com/joho/godotenv v1.5.1
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"lol-team-backend/balance"
+	"lol-team-backend/ledger"
+	"net/http"
+)
+
+type GameRecordRequest struct {
+	SessionID string           `json:"sessionId"` // チーム分けをしたセッションID（Roundと合わせて指定するとその試合のチームを使う）
+	Round     int              `json:"round"`     // セッションの試合番号
+	Team1     []balance.Player `json:"team1"`     // セッションを指定しない場合のチーム1
+	Team2     []balance.Player `json:"team2"`     // セッションを指定しない場合のチーム2
+	Winner    int              `json:"winner"`    // 勝ったチーム（1または2）
+}
+
+type InhouseRatingsRequest struct {
+	IDs []string `json:"ids"` // 空の場合は全員
+}
+
+type InhouseGamesRequest struct {
+	Limit int `json:"limit"` // 0の場合は全件
+}
+
+type GameDeleteRequest struct {
+	GameID string `json:"gameId"`
+}
+
+// inhouseLedger は身内戦の試合結果とレーティング（main で開く）
+var inhouseLedger *ledger.Ledger
+
+func recordGameHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req GameRecordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Received game record request - SessionID: %s, Round: %d, Winner: %d\n",
+		req.SessionID, req.Round, req.Winner)
+
+	team1, team2 := req.Team1, req.Team2
+	if req.SessionID != "" && req.Round > 0 {
+		s, ok := sessionStore.Get(req.SessionID)
+		if !ok {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		if req.Round > len(s.Rounds) || s.Rounds[req.Round-1].Split == nil {
+			http.Error(w, "Round not found", http.StatusNotFound)
+			return
+		}
+		split := s.Rounds[req.Round-1].Split
+		team1, team2 = split.Team1.Players, split.Team2.Players
+	}
+
+	game, err := inhouseLedger.Record(team1, team2, req.Winner, req.SessionID)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to record game: %v\n", err)
+		http.Error(w, fmt.Sprintf("Failed to record game: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Game recorded - ID: %s, Winner: %d, WinProbability: %.2f\n",
+		game.ID, game.Winner, game.WinProbability)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(game)
+}
+
+func getInhouseRatingsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req InhouseRatingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inhouseLedger.Ratings(req.IDs))
+}
+
+func getInhouseGamesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req InhouseGamesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inhouseLedger.Games(req.Limit))
+}
+
+func deleteGameHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req GameDeleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := inhouseLedger.Delete(req.GameID); err != nil {
+		fmt.Printf("ERROR: Failed to delete game: %v\n", err)
+		http.Error(w, fmt.Sprintf("Failed to delete game: %v", err), http.StatusNotFound)
+		return
+	}
+
+	fmt.Printf("INFO: Game deleted - ID: %s\n", req.GameID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// applyRatingSource はリクエストで指定されたレーティングの出どころをプレイヤーに反映する
+func applyRatingSource(w http.ResponseWriter, players []balance.Player, src ledger.Source) ([]balance.Player, bool) {
+	if err := src.Validate(); err != nil {
+		fmt.Printf("ERROR: Invalid rating source: %v\n", err)
+		http.Error(w, fmt.Sprintf("Invalid rating source: %v", err), http.StatusBadRequest)
+		return nil, false
+	}
+	return inhouseLedger.Apply(players, src), true
+}
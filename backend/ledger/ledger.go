@@ -0,0 +1,370 @@
+package ledger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"lol-team-backend/balance"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 身内戦レーティングの更新の係数（レーティングは balance.DefaultWinScale の尺度）
+const (
+	ProvisionalGames = 10  // この試合数まではレーティングを大きく動かす
+	ProvisionalK     = 100 // 暫定期間の1試合あたりの最大変動
+	DefaultK         = 50  // 暫定期間以降の1試合あたりの最大変動
+)
+
+// PlayerRating はプレイヤーの身内戦レーティング
+type PlayerRating struct {
+	ID        string    `json:"id"`        // プレイヤーID（PUUID等、試合をまたいで同じID）
+	Name      string    `json:"name"`      // 表示名（最後に記録した試合のもの）
+	Rating    int       `json:"rating"`    // 身内戦レーティング
+	Initial   int       `json:"initial"`   // 初めて記録したときのレーティング（ランクから）
+	Games     int       `json:"games"`     // 記録した試合数
+	Wins      int       `json:"wins"`      // 勝利数
+	Losses    int       `json:"losses"`    // 敗北数
+	UpdatedAt time.Time `json:"updatedAt"` // 最終更新日時
+}
+
+// RatingChange は1試合でのプレイヤーのレーティングの変化
+type RatingChange struct {
+	ID     string `json:"id"`     // プレイヤーID
+	Before int    `json:"before"` // 試合前のレーティング
+	After  int    `json:"after"`  // 試合後のレーティング
+}
+
+// Game は記録した身内戦の1試合
+type Game struct {
+	ID             string         `json:"id"`                  // 試合ID
+	SessionID      string         `json:"sessionId,omitempty"` // チーム分けをしたセッションID（任意）
+	Team1          []string       `json:"team1"`               // チーム1のプレイヤーID
+	Team2          []string       `json:"team2"`               // チーム2のプレイヤーID
+	Winner         int            `json:"winner"`              // 勝ったチーム（1または2）
+	PlayedAt       time.Time      `json:"playedAt"`            // 記録日時
+	WinProbability float64        `json:"winProbability"`      // 試合前のチーム1の予測勝率
+	Changes        []RatingChange `json:"changes"`             // プレイヤーごとのレーティングの変化
+}
+
+// Ledger は身内戦の試合結果とレーティングの保存先
+// 変更のたびにJSONファイルへ書き出し、再起動後も引き継ぐ
+type Ledger struct {
+	mu      sync.Mutex
+	path    string
+	players map[string]*PlayerRating
+	games   []Game
+}
+
+// ledgerFile は保存ファイルの形式
+type ledgerFile struct {
+	Players []PlayerRating `json:"players"`
+	Games   []Game         `json:"games"`
+}
+
+// Open は保存ファイルを読み込んでレジャーを開く（ファイルがない場合は空のレジャー）
+func Open(path string) (*Ledger, error) {
+	l := &Ledger{
+		path:    path,
+		players: make(map[string]*PlayerRating),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("レジャーの読み込みに失敗: %w", err)
+	}
+
+	var file ledgerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("レジャーの形式が不正です: %w", err)
+	}
+	for i := range file.Players {
+		p := file.Players[i]
+		l.players[p.ID] = &p
+	}
+	l.games = file.Games
+	return l, nil
+}
+
+// Record は試合結果を記録し、両チームのプレイヤーのレーティングを更新する
+// 保存に失敗した場合は記録前の状態に戻してエラーを返す
+// 初めて記録するプレイヤーはPlayer.Rating（ランクから）を初期値にする
+// winner: 勝ったチーム（1または2）
+func (l *Ledger) Record(team1, team2 []balance.Player, winner int, sessionID string) (*Game, error) {
+	if err := validateGame(team1, team2, winner); err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("試合IDの生成に失敗: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	players, games := l.snapshot()
+
+	now := time.Now()
+	for _, p := range append(append([]balance.Player{}, team1...), team2...) {
+		if existing, ok := l.players[p.ID]; ok {
+			if p.Name != "" {
+				existing.Name = p.Name
+			}
+			continue
+		}
+		l.players[p.ID] = &PlayerRating{
+			ID:        p.ID,
+			Name:      p.Name,
+			Rating:    p.Rating,
+			Initial:   p.Rating,
+			UpdatedAt: now,
+		}
+	}
+
+	game := Game{
+		ID:        id,
+		SessionID: sessionID,
+		Team1:     playerIDs(team1),
+		Team2:     playerIDs(team2),
+		Winner:    winner,
+		PlayedAt:  now,
+	}
+	l.apply(&game)
+	l.games = append(l.games, game)
+
+	if err := l.save(); err != nil {
+		l.players, l.games = players, games
+		return nil, err
+	}
+	return cloneGame(game), nil
+}
+
+// Delete は記録した試合を削除し、残りの試合から全員のレーティングを計算し直す
+// 保存に失敗した場合は削除前の状態に戻してエラーを返す
+func (l *Ledger) Delete(gameID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	index := -1
+	for i, g := range l.games {
+		if g.ID == gameID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("試合が見つかりません: %s", gameID)
+	}
+
+	players, games := l.snapshot()
+	l.games = append(l.games[:index], l.games[index+1:]...)
+
+	// 初期値から残りの試合を順に適用し直す
+	for _, p := range l.players {
+		p.Rating = p.Initial
+		p.Games, p.Wins, p.Losses = 0, 0, 0
+	}
+	for i := range l.games {
+		l.apply(&l.games[i])
+	}
+
+	if err := l.save(); err != nil {
+		l.players, l.games = players, games
+		return err
+	}
+	return nil
+}
+
+// Ratings は指定したプレイヤーのレーティングを返す（idsが空の場合は全員、レーティングの高い順）
+// 記録のないプレイヤーは含まない
+func (l *Ledger) Ratings(ids []string) []PlayerRating {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ratings := []PlayerRating{}
+	if len(ids) == 0 {
+		for _, p := range l.players {
+			ratings = append(ratings, *p)
+		}
+	} else {
+		for _, id := range ids {
+			if p, ok := l.players[id]; ok {
+				ratings = append(ratings, *p)
+			}
+		}
+	}
+
+	sort.SliceStable(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].ID < ratings[j].ID
+	})
+	return ratings
+}
+
+// Games は記録した試合を新しい順に最大limit件返す（0以下の場合は全件）
+func (l *Ledger) Games(limit int) []Game {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	games := []Game{}
+	for i := len(l.games) - 1; i >= 0; i-- {
+		if limit > 0 && len(games) >= limit {
+			break
+		}
+		games = append(games, *cloneGame(l.games[i]))
+	}
+	return games
+}
+
+// apply は試合結果でプレイヤーのレーティングを更新し、変化をgameに記録する（ロック取得済みで呼ぶ）
+// チーム全員が同じ期待勝率（チームの実効レーティングから予測）に対する結果で更新され、
+// 試合数が少ないプレイヤーほど大きく動く
+func (l *Ledger) apply(game *Game) {
+	ratings := func(ids []string) []int {
+		r := make([]int, len(ids))
+		for i, id := range ids {
+			r[i] = l.players[id].Rating
+		}
+		return r
+	}
+	prediction := balance.WinProbability(ratings(game.Team1), ratings(game.Team2), balance.DefaultWinScale)
+	game.WinProbability = prediction.Team1
+
+	game.Changes = game.Changes[:0]
+	for team, ids := range [][]string{game.Team1, game.Team2} {
+		expected, score := prediction.Team1, 0.0
+		if team == 1 {
+			expected = prediction.Team2
+		}
+		won := game.Winner == team+1
+		if won {
+			score = 1
+		}
+
+		for _, id := range ids {
+			p := l.players[id]
+			k := float64(DefaultK)
+			if p.Games < ProvisionalGames {
+				k = ProvisionalK
+			}
+
+			before := p.Rating
+			p.Rating += int(math.Round(k * (score - expected)))
+			p.Games++
+			if won {
+				p.Wins++
+			} else {
+				p.Losses++
+			}
+			p.UpdatedAt = game.PlayedAt
+			game.Changes = append(game.Changes, RatingChange{ID: id, Before: before, After: p.Rating})
+		}
+	}
+}
+
+// snapshot はプレイヤーと試合のコピーを返す（ロック取得済みで呼ぶ）
+// 保存に失敗した場合に変更前の状態へ戻すために使う
+func (l *Ledger) snapshot() (map[string]*PlayerRating, []Game) {
+	players := make(map[string]*PlayerRating, len(l.players))
+	for id, p := range l.players {
+		copied := *p
+		players[id] = &copied
+	}
+	games := make([]Game, len(l.games))
+	for i, g := range l.games {
+		games[i] = *cloneGame(g)
+	}
+	return players, games
+}
+
+// save はレジャーを一時ファイルに書き出してから置き換える（ロック取得済みで呼ぶ）
+func (l *Ledger) save() error {
+	file := ledgerFile{Players: make([]PlayerRating, 0, len(l.players)), Games: l.games}
+	for _, p := range l.players {
+		file.Players = append(file.Players, *p)
+	}
+	sort.Slice(file.Players, func(i, j int) bool { return file.Players[i].ID < file.Players[j].ID })
+	if file.Games == nil {
+		file.Games = []Game{}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("レジャーの書き出しに失敗: %w", err)
+	}
+
+	if dir := filepath.Dir(l.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("レジャーの保存先の作成に失敗: %w", err)
+		}
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("レジャーの書き出しに失敗: %w", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("レジャーの書き出しに失敗: %w", err)
+	}
+	return nil
+}
+
+// validateGame は試合結果の両チームと勝者が正しいかチェックする
+func validateGame(team1, team2 []balance.Player, winner int) error {
+	if winner != 1 && winner != 2 {
+		return fmt.Errorf("勝ったチームは1または2で指定してください: %d", winner)
+	}
+	if len(team1) == 0 || len(team2) == 0 {
+		return fmt.Errorf("両チームに1人以上のプレイヤーが必要です")
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range append(append([]balance.Player{}, team1...), team2...) {
+		if p.ID == "" {
+			return fmt.Errorf("プレイヤーIDが空です")
+		}
+		if seen[p.ID] {
+			return fmt.Errorf("プレイヤーIDが重複しています: %s", p.ID)
+		}
+		seen[p.ID] = true
+	}
+	return nil
+}
+
+// cloneGame は試合のコピーを返す
+func cloneGame(g Game) *Game {
+	g.Team1 = append([]string(nil), g.Team1...)
+	g.Team2 = append([]string(nil), g.Team2...)
+	g.Changes = append([]RatingChange(nil), g.Changes...)
+	return &g
+}
+
+// playerIDs はプレイヤーIDの一覧を返す
+func playerIDs(players []balance.Player) []string {
+	ids := make([]string, len(players))
+	for i, p := range players {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+// newID はランダムな試合IDを生成する
+func newID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,160 @@
+package ledger
+
+import (
+	"fmt"
+	"lol-team-backend/balance"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testTeams は5人ずつのチームを返す（チーム1はp0-p4、チーム2はp5-p9）
+func testTeams() ([]balance.Player, []balance.Player) {
+	players := make([]balance.Player, 10)
+	for i := range players {
+		players[i] = balance.Player{
+			ID:     fmt.Sprintf("p%d", i),
+			Name:   fmt.Sprintf("Player%d", i),
+			Rating: 1000 + i*100,
+		}
+	}
+	return players[:5], players[5:]
+}
+
+func openTemp(t *testing.T) (*Ledger, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, path
+}
+
+// sameRatings はレーティングの一覧が等しいか判定する（更新日時はモノトニック時刻を除いて比較）
+func sameRatings(a, b []PlayerRating) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		if !x.UpdatedAt.Equal(y.UpdatedAt) {
+			return false
+		}
+		x.UpdatedAt = y.UpdatedAt
+		if x != y {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	l, path := openTemp(t)
+	team1, team2 := testTeams()
+
+	if _, err := l.Record(team1, team2, 1, "session"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Record(team1, team2, 2, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reopened.Ratings(nil), l.Ratings(nil); !sameRatings(got, want) {
+		t.Errorf("ratings after reopen = %+v, want %+v", got, want)
+	}
+	got, want := reopened.Games(0), l.Games(0)
+	if len(got) != len(want) {
+		t.Fatalf("games after reopen = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].Winner != want[i].Winner ||
+			got[i].SessionID != want[i].SessionID || !reflect.DeepEqual(got[i].Changes, want[i].Changes) {
+			t.Errorf("game %d after reopen = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDeleteRecomputes(t *testing.T) {
+	l, _ := openTemp(t)
+	team1, team2 := testTeams()
+
+	first, err := l.Record(team1, team2, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Record(team1, team2, 2, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Delete(first.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2試合目だけを記録した場合と同じレーティングになる
+	fresh, _ := openTemp(t)
+	if _, err := fresh.Record(team1, team2, 2, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	got, want := l.Ratings(nil), fresh.Ratings(nil)
+	if len(got) != len(want) {
+		t.Fatalf("ratings = %d players, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].Rating != want[i].Rating || got[i].Games != 1 ||
+			got[i].Wins != want[i].Wins || got[i].Losses != want[i].Losses {
+			t.Errorf("rating %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if games := l.Games(0); len(games) != 1 || games[0].Winner != 2 {
+		t.Errorf("games after delete = %+v", games)
+	}
+
+	if err := l.Delete(first.ID); err == nil {
+		t.Error("expected an error deleting a game twice")
+	}
+}
+
+func TestSaveFailureLeavesStateUnchanged(t *testing.T) {
+	l, path := openTemp(t)
+	team1, team2 := testTeams()
+
+	game, err := l.Record(team1, team2, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ratings, games := l.Ratings(nil), l.Games(0)
+
+	// 保存先をディレクトリにして書き出し（置き換え）を失敗させる
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocker"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := l.Record(team1, team2, 2, ""); err == nil {
+		t.Fatal("expected Record to fail when the ledger cannot be saved")
+	}
+	if got := l.Ratings(nil); !reflect.DeepEqual(got, ratings) {
+		t.Errorf("ratings after failed Record = %+v, want %+v", got, ratings)
+	}
+	if got := l.Games(0); !reflect.DeepEqual(got, games) {
+		t.Errorf("games after failed Record = %+v, want %+v", got, games)
+	}
+
+	if err := l.Delete(game.ID); err == nil {
+		t.Fatal("expected Delete to fail when the ledger cannot be saved")
+	}
+	if got := l.Ratings(nil); !reflect.DeepEqual(got, ratings) {
+		t.Errorf("ratings after failed Delete = %+v, want %+v", got, ratings)
+	}
+	if got := l.Games(0); !reflect.DeepEqual(got, games) {
+		t.Errorf("games after failed Delete = %+v, want %+v", got, games)
+	}
+}
@@ -0,0 +1,81 @@
+package ledger
+
+import (
+	"fmt"
+	"lol-team-backend/balance"
+	"math"
+)
+
+// チーム分けに使うレーティングの出どころ
+const (
+	SourceRank    = "rank"    // ランクから求めたレーティング（Player.Rating、デフォルト）
+	SourceInhouse = "inhouse" // 身内戦レーティング（記録のないプレイヤーはランクから）
+	SourceBlend   = "blend"   // ランクと身内戦レーティングの加重平均
+)
+
+// DefaultInhouseWeight はblendでの身内戦レーティングのデフォルトの重み
+const DefaultInhouseWeight = 0.5
+
+// Source はチーム分けに使うレーティングの指定
+type Source struct {
+	// RatingSource はレーティングの出どころ（"rank"、"inhouse"、"blend"）
+	RatingSource string `json:"ratingSource,omitempty"`
+	// InhouseWeight はblendでの身内戦レーティングの重み（0-1、デフォルト: 0.5）
+	InhouseWeight float64 `json:"inhouseWeight,omitempty"`
+}
+
+// Validate はレーティングの指定が正しいかチェックする
+func (s Source) Validate() error {
+	switch s.RatingSource {
+	case "", SourceRank, SourceInhouse, SourceBlend:
+	default:
+		return fmt.Errorf("不明なレーティングの出どころです: %s", s.RatingSource)
+	}
+	if s.InhouseWeight < 0 || s.InhouseWeight > 1 {
+		return fmt.Errorf("身内戦レーティングの重みは0から1で指定してください: %v", s.InhouseWeight)
+	}
+	return nil
+}
+
+// Apply はプレイヤーのレーティングを指定の出どころのものに置き換えたコピーを返す
+// ロール別MMRは置き換え前後のレーティングの差だけずらす。記録のないプレイヤーはそのまま
+// ARAMのレーティングも同じ重みで置き換えるので、ARAMモードでRatingをARAMのレーティングに
+// 差し替えた後でも指定の出どころが反映される
+func (l *Ledger) Apply(players []balance.Player, src Source) []balance.Player {
+	if src.RatingSource == "" || src.RatingSource == SourceRank {
+		return players
+	}
+	weight := 1.0
+	if src.RatingSource == SourceBlend {
+		weight = src.InhouseWeight
+		if weight == 0 {
+			weight = DefaultInhouseWeight
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	applied := make([]balance.Player, len(players))
+	for i, p := range players {
+		applied[i] = p
+		entry, ok := l.players[p.ID]
+		if !ok || entry.Games == 0 {
+			continue
+		}
+
+		rating := int(math.Round((1-weight)*float64(p.Rating) + weight*float64(entry.Rating)))
+		offset := rating - p.Rating
+		applied[i].Rating = rating
+		if p.AramRating > 0 {
+			applied[i].AramRating = int(math.Round((1-weight)*float64(p.AramRating) + weight*float64(entry.Rating)))
+		}
+		if len(p.RoleRatings) > 0 {
+			applied[i].RoleRatings = make(map[string]int, len(p.RoleRatings))
+			for role, r := range p.RoleRatings {
+				applied[i].RoleRatings[role] = r + offset
+			}
+		}
+	}
+	return applied
+}
@@ -0,0 +1,45 @@
+package ledger
+
+import (
+	"lol-team-backend/balance"
+	"testing"
+)
+
+func TestApplyReplacesAramRating(t *testing.T) {
+	l, _ := openTemp(t)
+	team1, team2 := testTeams()
+	if _, err := l.Record(team1, team2, 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	inhouse := l.Ratings([]string{"p0"})[0].Rating
+
+	player := team1[0]
+	player.AramRating = 3000
+
+	applied := l.Apply([]balance.Player{player}, Source{RatingSource: SourceInhouse})
+	if applied[0].Rating != inhouse || applied[0].AramRating != inhouse {
+		t.Errorf("inhouse: rating %d, aram %d, want both %d", applied[0].Rating, applied[0].AramRating, inhouse)
+	}
+
+	applied = l.Apply([]balance.Player{player}, Source{RatingSource: SourceBlend, InhouseWeight: 0.5})
+	if want := (3000 + inhouse) / 2; applied[0].AramRating != want {
+		t.Errorf("blend: aram %d, want %d", applied[0].AramRating, want)
+	}
+
+	// ARAMモードのチーム分けでも身内戦レーティングが使われる
+	players := append(append([]balance.Player{}, team1...), team2...)
+	for i := range players {
+		players[i].AramRating = 3000
+	}
+	split, err := balance.Divide(l.Apply(players, Source{RatingSource: SourceInhouse}), balance.Options{GameMode: balance.GameModeARAM, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, r := range l.Ratings(nil) {
+		total += r.Rating
+	}
+	if got := split.Team1.TotalRating + split.Team2.TotalRating; got != total {
+		t.Errorf("ARAM split total rating = %d, want inhouse total %d", got, total)
+	}
+}
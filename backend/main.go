@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"lol-team-backend/ledger"
+	"lol-team-backend/rating"
 	"lol-team-backend/riotapi"
 	"net/http"
 	"os"
@@ -15,30 +17,89 @@ import (
 )
 
 type RankRequest struct {
-	GameName string `json:"gameName"`
-	TagLine  string `json:"tagLine"`
+	GameName string              `json:"gameName"`
+	TagLine  string              `json:"tagLine"`
+	Blend    riotapi.BlendPolicy `json:"blend"` // ソロ・フレックスのブレンド方針
 }
 
 type RankResponse struct {
-	Tier        string `json:"tier"`
-	Rank        string `json:"rank"`
-	LP          int    `json:"lp"`
-	Rating      int    `json:"rating"`
-	ProfileIcon int    `json:"profileIcon"`
+	Tier        string                      `json:"tier"`
+	Rank        string                      `json:"rank"`
+	LP          int                         `json:"lp"`
+	Rating      int                         `json:"rating"`
+	ProfileIcon int                         `json:"profileIcon"`
+	Policy      string                      `json:"policy"` // 使用したブレンド方針
+	Queues      []riotapi.QueueContribution `json:"queues"` // レーティングに寄与したキュー
 }
 
 type RoleMMRRequest struct {
 	PUUID      string `json:"puuid"`
 	Role       string `json:"role"`
 	MatchCount int    `json:"matchCount"`
+	riotapi.RoleMMROptions
 }
 
+type AllRoleMMRRequest struct {
+	PUUID      string `json:"puuid"`
+	MatchCount int    `json:"matchCount"`
+	riotapi.RoleMMROptions
+}
+
+type LaningRequest struct {
+	PUUID      string `json:"puuid"`
+	MatchCount int    `json:"matchCount"`
+	riotapi.LaningOptions
+}
+
+type ARAMRatingRequest struct {
+	PUUID      string              `json:"puuid"`
+	MatchCount int                 `json:"matchCount"`
+	Blend      riotapi.BlendPolicy `json:"blend"` // ソロ・フレックスのブレンド方針
+}
+
+// プレイヤーを探す地域（この順に試す）と対応する大陸
+var (
+	regions    = []string{"jp1", "kr", "na1", "euw1", "eun1", "br1", "la1", "la2", "oc1", "tr1", "ru"}
+	continents = map[string]string{
+		"jp1":  "asia",
+		"kr":   "asia",
+		"na1":  "americas",
+		"br1":  "americas",
+		"la1":  "americas",
+		"la2":  "americas",
+		"euw1": "europe",
+		"eun1": "europe",
+		"tr1":  "europe",
+		"ru":   "europe",
+		"oc1":  "sea",
+	}
+)
+
 var (
 	riotAPIKey   string
 	globalClient *riotapi.Client
 	clientMutex  sync.Mutex
 )
 
+// regionClients は地域ごとのクライアント（キャッシュとレート制限をリクエストをまたいで共有する）
+var (
+	regionClients   = make(map[string]*riotapi.Client)
+	regionClientsMu sync.Mutex
+)
+
+// regionClient は地域のクライアントを返す（初回のみ作成）
+func regionClient(region string) *riotapi.Client {
+	regionClientsMu.Lock()
+	defer regionClientsMu.Unlock()
+
+	client, ok := regionClients[region]
+	if !ok {
+		client = riotapi.NewClient(riotAPIKey, region, continents[region])
+		regionClients[region] = client
+	}
+	return client
+}
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: .env file not found, using environment variables")
@@ -54,11 +115,46 @@ func main() {
 	riotAPIKey = apiKey
 	globalClient = riotapi.NewClient(apiKey, "jp1", "asia")
 
+	// レーティングの対応（RATING_SCALEで上書き）
+	if err := loadRatingScale(); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+	log.Printf("INFO: Rating scale: %+v\n", rating.DefaultScale)
+
+	// 身内戦のレジャー（ファイルがなければ最初の記録時に作成）
+	ledgerPath := os.Getenv("LEDGER_PATH")
+	if ledgerPath == "" {
+		ledgerPath = "data/ledger.json"
+	}
+	l, err := ledger.Open(ledgerPath)
+	if err != nil {
+		log.Fatalf("ERROR: Failed to open in-house ledger: %v", err)
+	}
+	inhouseLedger = l
+	log.Printf("INFO: In-house ledger opened: %s\n", ledgerPath)
+
 	allowedOrigins := getAllowedOrigins()
 
 	// 通常のエンドポイント（CORS制限あり）
 	http.HandleFunc("/api/rank", corsMiddleware(getRankHandler, allowedOrigins))
 	http.HandleFunc("/api/role-mmr", corsMiddleware(getRoleMMRHandler, allowedOrigins))
+	http.HandleFunc("/api/role-mmr/all", corsMiddleware(getAllRoleMMRsHandler, allowedOrigins))
+	http.HandleFunc("/api/laning", corsMiddleware(getLaningHandler, allowedOrigins))
+	http.HandleFunc("/api/aram-rating", corsMiddleware(getARAMRatingHandler, allowedOrigins))
+	http.HandleFunc("/api/teams/divide", corsMiddleware(divideTeamsHandler, allowedOrigins))
+	http.HandleFunc("/api/teams/lobbies", corsMiddleware(divideLobbiesHandler, allowedOrigins))
+	http.HandleFunc("/api/teams/arena", corsMiddleware(divideArenaHandler, allowedOrigins))
+	http.HandleFunc("/api/teams/predict", corsMiddleware(predictWinHandler, allowedOrigins))
+	http.HandleFunc("/api/draft/start", corsMiddleware(startDraftHandler, allowedOrigins))
+	http.HandleFunc("/api/draft/pick", corsMiddleware(pickDraftHandler, allowedOrigins))
+	http.HandleFunc("/api/sessions/create", corsMiddleware(createSessionHandler, allowedOrigins))
+	http.HandleFunc("/api/sessions/get", corsMiddleware(getSessionHandler, allowedOrigins))
+	http.HandleFunc("/api/sessions/divide", corsMiddleware(divideSessionHandler, allowedOrigins))
+	http.HandleFunc("/api/inhouse/record", corsMiddleware(recordGameHandler, allowedOrigins))
+	http.HandleFunc("/api/inhouse/ratings", corsMiddleware(getInhouseRatingsHandler, allowedOrigins))
+	http.HandleFunc("/api/inhouse/games", corsMiddleware(getInhouseGamesHandler, allowedOrigins))
+	http.HandleFunc("/api/inhouse/delete", corsMiddleware(deleteGameHandler, allowedOrigins))
+	http.HandleFunc("/api/rating/tier", corsMiddleware(convertTierHandler, allowedOrigins))
 
 	// ヘルスチェック用エンドポイント（CORS制限なし - Cron Job用）
 	http.HandleFunc("/api/health", healthCheckHandler)
@@ -112,7 +208,7 @@ func corsMiddleware(next http.HandlerFunc, allowedOrigins []string) http.Handler
 		}
 
 		w.Header().Set("Access-Control-Allow-Origin", origin)
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
@@ -149,19 +245,10 @@ func getRankHandler(w http.ResponseWriter, r *http.Request) {
 
 	fmt.Printf("INFO: Received request - GameName: %s, TagLine: %s\n", req.GameName, req.TagLine)
 
-	regions := []string{"jp1", "kr", "na1", "euw1", "eun1", "br1", "la1", "la2", "oc1", "tr1", "ru"}
-	continents := map[string]string{
-		"jp1":  "asia",
-		"kr":   "asia",
-		"na1":  "americas",
-		"br1":  "americas",
-		"la1":  "americas",
-		"la2":  "americas",
-		"euw1": "europe",
-		"eun1": "europe",
-		"tr1":  "europe",
-		"ru":   "europe",
-		"oc1":  "sea",
+	if err := req.Blend.Validate(); err != nil {
+		fmt.Printf("ERROR: Invalid blend policy: %v\n", err)
+		http.Error(w, "Invalid blend policy. Must be one of: solo, games, max", http.StatusBadRequest)
+		return
 	}
 
 	var rankInfo *RankResponse
@@ -209,7 +296,7 @@ func getRankHandler(w http.ResponseWriter, r *http.Request) {
 		var bestEntry *riotapi.LeagueEntry
 		for i := range entries {
 			entry := &entries[i]
-			if entry.QueueType == "RANKED_SOLO_5x5" {
+			if entry.QueueType == riotapi.QueueTypeSolo {
 				bestEntry = entry
 				break
 			}
@@ -219,22 +306,27 @@ func getRankHandler(w http.ResponseWriter, r *http.Request) {
 			bestEntry = &entries[0]
 		}
 
+		base := riotapi.BlendRating(entries, req.Blend)
+
 		if bestEntry == nil {
 			rankInfo = &RankResponse{
 				Tier:        "UNRANKED",
 				Rank:        "",
 				LP:          0,
-				Rating:      0,
+				Rating:      base.Rating,
 				ProfileIcon: summonerInfo.ProfileIconID,
+				Policy:      base.Policy,
+				Queues:      base.Queues,
 			}
 		} else {
-			rating := tierToRating(bestEntry.Tier, bestEntry.Rank, bestEntry.LeaguePoints)
 			rankInfo = &RankResponse{
 				Tier:        bestEntry.Tier,
 				Rank:        bestEntry.Rank,
 				LP:          bestEntry.LeaguePoints,
-				Rating:      rating,
+				Rating:      base.Rating,
 				ProfileIcon: summonerInfo.ProfileIconID,
+				Policy:      base.Policy,
+				Queues:      base.Queues,
 			}
 		}
 
@@ -290,23 +382,14 @@ func getRoleMMRHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.MatchCount <= 0 {
-		req.MatchCount = 20
+	if err := req.RoleMMROptions.Validate(); err != nil {
+		fmt.Printf("ERROR: Invalid role MMR options: %v\n", err)
+		http.Error(w, fmt.Sprintf("Invalid role MMR options: %v", err), http.StatusBadRequest)
+		return
 	}
 
-	regions := []string{"jp1", "kr", "na1", "euw1", "eun1", "br1", "la1", "la2", "oc1", "tr1", "ru"}
-	continents := map[string]string{
-		"jp1":  "asia",
-		"kr":   "asia",
-		"na1":  "americas",
-		"br1":  "americas",
-		"la1":  "americas",
-		"la2":  "americas",
-		"euw1": "europe",
-		"eun1": "europe",
-		"tr1":  "europe",
-		"ru":   "europe",
-		"oc1":  "sea",
+	if req.MatchCount <= 0 {
+		req.MatchCount = 20
 	}
 
 	var mmrResult *riotapi.RoleMMRResult
@@ -316,9 +399,9 @@ func getRoleMMRHandler(w http.ResponseWriter, r *http.Request) {
 		continent := continents[region]
 		fmt.Printf("INFO: Trying region %s (continent: %s) for role MMR\n", region, continent)
 
-		client := riotapi.NewClient(riotAPIKey, region, continent)
+		client := regionClient(region)
 
-		result, err := client.GetRoleMMR(req.PUUID, req.Role, req.MatchCount)
+		result, err := client.GetRoleMMR(req.PUUID, req.Role, req.MatchCount, req.RoleMMROptions)
 		if err != nil {
 			fmt.Printf("INFO: Failed to get role MMR in region %s: %v\n", region, err)
 			lastError = err
@@ -327,8 +410,8 @@ func getRoleMMRHandler(w http.ResponseWriter, r *http.Request) {
 
 		if result.GamesPlayed > 0 {
 			mmrResult = result
-			fmt.Printf("INFO: Successfully retrieved role MMR from region %s: MMR=%d, Games=%d\n",
-				region, result.MMR, result.GamesPlayed)
+			fmt.Printf("INFO: Successfully retrieved role MMR from region %s: MMR=%d, Deviation=%d, Games=%d, Excluded=%d\n",
+				region, result.MMR, result.Deviation, result.GamesPlayed, result.Excluded.Total())
 			break
 		}
 
@@ -347,29 +430,195 @@ func getRoleMMRHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(mmrResult)
 }
 
-func tierToRating(tier, rank string, lp int) int {
-	tierValues := map[string]int{
-		"IRON":        0,
-		"BRONZE":      400,
-		"SILVER":      800,
-		"GOLD":        1200,
-		"PLATINUM":    1600,
-		"EMERALD":     2000,
-		"DIAMOND":     2400,
-		"MASTER":      2800,
-		"GRANDMASTER": 3200,
-		"CHALLENGER":  3600,
+func getAllRoleMMRsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	rankValues := map[string]int{
-		"IV":  0,
-		"III": 100,
-		"II":  200,
-		"I":   300,
+	var req AllRoleMMRRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
 	}
 
-	tierVal := tierValues[tier]
-	rankVal := rankValues[rank]
+	fmt.Printf("INFO: Received all-roles MMR request - PUUID: %s, MatchCount: %d\n", req.PUUID, req.MatchCount)
 
-	return tierVal + rankVal + lp
+	if err := req.RoleMMROptions.Validate(); err != nil {
+		fmt.Printf("ERROR: Invalid role MMR options: %v\n", err)
+		http.Error(w, fmt.Sprintf("Invalid role MMR options: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if req.MatchCount <= 0 {
+		req.MatchCount = 20
+	}
+
+	var mmrResult *riotapi.AllRoleMMRResult
+	var lastError error
+
+	for _, region := range regions {
+		continent := continents[region]
+		fmt.Printf("INFO: Trying region %s (continent: %s) for all-roles MMR\n", region, continent)
+
+		client := regionClient(region)
+
+		result, err := client.GetAllRoleMMRs(req.PUUID, req.MatchCount, req.RoleMMROptions)
+		if err != nil {
+			fmt.Printf("INFO: Failed to get all-roles MMR in region %s: %v\n", region, err)
+			lastError = err
+			continue
+		}
+
+		if result.TotalGames > 0 {
+			mmrResult = result
+			fmt.Printf("INFO: Successfully retrieved all-roles MMR from region %s: MainRole=%s, Games=%d, Excluded=%d\n",
+				region, result.MainRole, result.TotalGames, result.Excluded.Total())
+			break
+		}
+
+		if mmrResult == nil {
+			mmrResult = result
+		}
+	}
+
+	if mmrResult == nil {
+		fmt.Printf("ERROR: Failed to get all-roles MMR from all regions: %v\n", lastError)
+		http.Error(w, fmt.Sprintf("Failed to get role MMR: %v", lastError), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mmrResult)
+}
+
+func getLaningHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LaningRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Received laning request - PUUID: %s, Role: %s, MatchCount: %d\n",
+		req.PUUID, req.Role, req.MatchCount)
+
+	validRoles := map[string]bool{
+		"": true, "TOP": true, "JUNGLE": true, "MID": true, "ADC": true, "SUPPORT": true,
+	}
+	if !validRoles[req.Role] {
+		fmt.Printf("ERROR: Invalid role: %s\n", req.Role)
+		http.Error(w, "Invalid role. Must be empty or one of: TOP, JUNGLE, MID, ADC, SUPPORT", http.StatusBadRequest)
+		return
+	}
+
+	if req.MatchCount <= 0 {
+		req.MatchCount = 20
+	}
+
+	var laningResult *riotapi.LaningResult
+	var lastError error
+
+	for _, region := range regions {
+		continent := continents[region]
+		fmt.Printf("INFO: Trying region %s (continent: %s) for laning\n", region, continent)
+
+		client := regionClient(region)
+
+		result, err := client.GetLaningStats(req.PUUID, req.MatchCount, req.LaningOptions)
+		if err != nil {
+			fmt.Printf("INFO: Failed to get laning stats in region %s: %v\n", region, err)
+			lastError = err
+			continue
+		}
+
+		if result.GamesAnalyzed > 0 {
+			laningResult = result
+			fmt.Printf("INFO: Successfully retrieved laning stats from region %s: Games=%d, Excluded=%d\n",
+				region, result.GamesAnalyzed, result.Excluded.Total())
+			break
+		}
+
+		if laningResult == nil {
+			laningResult = result
+		}
+	}
+
+	if laningResult == nil {
+		fmt.Printf("ERROR: Failed to get laning stats from all regions: %v\n", lastError)
+		http.Error(w, fmt.Sprintf("Failed to get laning stats: %v", lastError), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(laningResult)
+}
+
+func getARAMRatingHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ARAMRatingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Received ARAM rating request - PUUID: %s, MatchCount: %d\n", req.PUUID, req.MatchCount)
+
+	if err := req.Blend.Validate(); err != nil {
+		fmt.Printf("ERROR: Invalid blend policy: %v\n", err)
+		http.Error(w, "Invalid blend policy. Must be one of: solo, games, max", http.StatusBadRequest)
+		return
+	}
+
+	if req.MatchCount <= 0 {
+		req.MatchCount = 20
+	}
+
+	var aramResult *riotapi.ARAMRatingResult
+	var lastError error
+
+	for _, region := range regions {
+		continent := continents[region]
+		fmt.Printf("INFO: Trying region %s (continent: %s) for ARAM rating\n", region, continent)
+
+		client := regionClient(region)
+
+		result, err := client.GetARAMRating(req.PUUID, req.MatchCount, req.Blend)
+		if err != nil {
+			fmt.Printf("INFO: Failed to get ARAM rating in region %s: %v\n", region, err)
+			lastError = err
+			continue
+		}
+
+		if result.GamesPlayed > 0 {
+			aramResult = result
+			fmt.Printf("INFO: Successfully retrieved ARAM rating from region %s: Rating=%d, Games=%d, Excluded=%d\n",
+				region, result.Rating, result.GamesPlayed, result.Excluded.Total())
+			break
+		}
+
+		if aramResult == nil {
+			aramResult = result
+		}
+	}
+
+	if aramResult == nil {
+		fmt.Printf("ERROR: Failed to get ARAM rating from all regions: %v\n", lastError)
+		http.Error(w, fmt.Sprintf("Failed to get ARAM rating: %v", lastError), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(aramResult)
 }
@@ -0,0 +1,154 @@
+package rating
+
+import "math"
+
+// GlickoConfig はGlicko-2方式の更新のパラメータ（レーティングはこのパッケージの尺度）
+type GlickoConfig struct {
+	// WinScale はレーティング差がこの値のとき強い側の期待勝率が約91%（10:1）になる尺度
+	WinScale float64 `json:"winScale"`
+	// InitialDeviation は試合がない状態のレーティングの不確かさ（RD）。期間ごとの増加もこの値で頭打ちになる
+	InitialDeviation float64 `json:"initialDeviation"`
+	// MinDeviation はRDの下限（試合数が多くても不確かさが0にならないようにする）
+	MinDeviation float64 `json:"minDeviation"`
+	// InitialVolatility はレーティングの変動しやすさの初期値
+	InitialVolatility float64 `json:"initialVolatility"`
+	// Tau は変動しやすさの変化を抑える定数（小さいほど変化しにくい）
+	Tau float64 `json:"tau"`
+}
+
+// DefaultGlicko はデフォルトのパラメータ（RDの初期値は約1ティア分）
+var DefaultGlicko = GlickoConfig{
+	WinScale:          1000,
+	InitialDeviation:  350,
+	MinDeviation:      40,
+	InitialVolatility: 0.06,
+	Tau:               0.5,
+}
+
+// Glicko はレーティングとその不確かさ
+type Glicko struct {
+	Rating     float64 `json:"rating"`     // レーティング
+	Deviation  float64 `json:"deviation"`  // レーティングの不確かさ（RD、標準偏差に相当）
+	Volatility float64 `json:"volatility"` // レーティングの変動しやすさ
+}
+
+// GlickoResult はレーティング期間中の1試合の結果
+type GlickoResult struct {
+	Opponent          float64 // 相手のレーティング
+	OpponentDeviation float64 // 相手のRD
+	Score             float64 // 結果（勝ち: 1、引き分け: 0.5、負け: 0）
+	Weight            float64 // 試合の重み（古い試合ほど小さくする等。0は1として扱う）
+}
+
+// glickoEpsilon は変動しやすさの反復計算の収束判定
+const glickoEpsilon = 1e-6
+
+// NewGlicko は指定したレーティングから、不確かさが初期値の状態を返す
+func (c GlickoConfig) NewGlicko(rating float64) Glicko {
+	return Glicko{Rating: rating, Deviation: c.InitialDeviation, Volatility: c.InitialVolatility}
+}
+
+// Interval はレーティングの区間（rating ± z×RD）を返す
+func (g Glicko) Interval(z float64) (low, high float64) {
+	return g.Rating - z*g.Deviation, g.Rating + z*g.Deviation
+}
+
+// scale はレーティングとGlicko-2の内部尺度の比
+func (c GlickoConfig) scale() float64 {
+	return c.WinScale / math.Ln10
+}
+
+// Idle は試合のないレーティング期間をperiods回経過させる（RDだけが増える）
+func (c GlickoConfig) Idle(g Glicko, periods int) Glicko {
+	if periods <= 0 {
+		return g
+	}
+	k := c.scale()
+	phi := g.Deviation / k
+	phi = math.Sqrt(phi*phi + float64(periods)*g.Volatility*g.Volatility)
+	g.Deviation = math.Min(phi*k, c.InitialDeviation)
+	return g
+}
+
+// Update は1レーティング期間の試合結果でレーティング・RD・変動しやすさを更新する
+// 重みのある試合は尤度をその重みで割り引く（重み0.5の試合は半試合分の情報として扱う）
+// 結果がない場合はIdleと同じ
+func (c GlickoConfig) Update(g Glicko, results []GlickoResult) Glicko {
+	if len(results) == 0 {
+		return c.Idle(g, 1)
+	}
+
+	k := c.scale()
+	mu := g.Rating / k
+	phi := g.Deviation / k
+	sigma := g.Volatility
+
+	// 推定分散vと改善量delta
+	vInv := 0.0
+	sum := 0.0
+	for _, r := range results {
+		w := r.Weight
+		if w == 0 {
+			w = 1
+		}
+		gj := glickoG(r.OpponentDeviation / k)
+		e := 1 / (1 + math.Exp(-gj*(mu-r.Opponent/k)))
+		vInv += w * gj * gj * e * (1 - e)
+		sum += w * gj * (r.Score - e)
+	}
+	v := 1 / vInv
+	delta := v * sum
+
+	sigma = c.volatility(phi, sigma, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * sum
+
+	return Glicko{
+		Rating:     mu * k,
+		Deviation:  math.Max(math.Min(phi*k, c.InitialDeviation), c.MinDeviation),
+		Volatility: sigma,
+	}
+}
+
+// volatility は変動しやすさの新しい値を求める（Glickmanの論文のIllinois法）
+func (c GlickoConfig) volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	tau2 := c.Tau * c.Tau
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/tau2
+	}
+
+	lo := a
+	var hi float64
+	if delta*delta > phi*phi+v {
+		hi = math.Log(delta*delta - phi*phi - v)
+	} else {
+		n := 1.0
+		for f(a-n*c.Tau) < 0 {
+			n++
+		}
+		hi = a - n*c.Tau
+	}
+
+	fLo, fHi := f(lo), f(hi)
+	for math.Abs(hi-lo) > glickoEpsilon {
+		mid := lo + (lo-hi)*fLo/(fHi-fLo)
+		fMid := f(mid)
+		if fMid*fHi <= 0 {
+			lo, fLo = hi, fHi
+		} else {
+			fLo /= 2
+		}
+		hi, fHi = mid, fMid
+	}
+	return math.Exp(lo / 2)
+}
+
+// glickoG は相手のRDによる期待勝率の割り引き
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
@@ -0,0 +1,86 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGlickoUpdatePaperExample(t *testing.T) {
+	// Glickmanの論文の計算例（Elo尺度: 400で10:1）
+	c := GlickoConfig{WinScale: 400, InitialDeviation: 350, InitialVolatility: 0.06, Tau: 0.5}
+	g := c.Update(Glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}, []GlickoResult{
+		{Opponent: 1400, OpponentDeviation: 30, Score: 1},
+		{Opponent: 1550, OpponentDeviation: 100, Score: 0},
+		{Opponent: 1700, OpponentDeviation: 300, Score: 0},
+	})
+
+	if math.Abs(g.Rating-1464.06) > 0.05 {
+		t.Errorf("Rating = %.2f, want 1464.06", g.Rating)
+	}
+	if math.Abs(g.Deviation-151.52) > 0.05 {
+		t.Errorf("Deviation = %.2f, want 151.52", g.Deviation)
+	}
+	if math.Abs(g.Volatility-0.05999) > 0.00001 {
+		t.Errorf("Volatility = %.5f, want 0.05999", g.Volatility)
+	}
+}
+
+func TestGlickoDeviation(t *testing.T) {
+	c := DefaultGlicko
+	g := c.NewGlicko(1500)
+
+	// 試合を重ねるとRDは下がる（1期間1試合では変動しやすさとの釣り合いで止まる）
+	for i := 0; i < 200; i++ {
+		next := c.Update(g, []GlickoResult{{Opponent: 1500, OpponentDeviation: 100, Score: float64(i % 2)}})
+		if i < 10 && next.Deviation >= g.Deviation {
+			t.Fatalf("period %d: Deviation rose from %.2f to %.2f", i, g.Deviation, next.Deviation)
+		}
+		g = next
+	}
+	if g.Deviation > c.InitialDeviation/2 || g.Deviation < c.MinDeviation {
+		t.Errorf("Deviation after 200 games = %.2f, want between %.2f and %.2f",
+			g.Deviation, c.MinDeviation, c.InitialDeviation/2)
+	}
+	if math.Abs(g.Rating-1500) > 50 {
+		t.Errorf("Rating after even results = %.2f, want near 1500", g.Rating)
+	}
+
+	// 試合がない期間はRDが増え、初期値で頭打ちになる
+	idle := c.Idle(g, 30)
+	if idle.Deviation <= g.Deviation || idle.Rating != g.Rating {
+		t.Errorf("Idle(30) = %+v, want larger deviation than %+v", idle, g)
+	}
+	if got := c.Idle(g, 100000).Deviation; got != c.InitialDeviation {
+		t.Errorf("Idle(100000).Deviation = %.2f, want %.2f", got, c.InitialDeviation)
+	}
+}
+
+func TestGlickoWinsRaiseRating(t *testing.T) {
+	c := DefaultGlicko
+	win := c.Update(c.NewGlicko(1500), []GlickoResult{{Opponent: 1500, OpponentDeviation: 100, Score: 1}})
+	loss := c.Update(c.NewGlicko(1500), []GlickoResult{{Opponent: 1500, OpponentDeviation: 100, Score: 0}})
+
+	if win.Rating <= 1500 || loss.Rating >= 1500 {
+		t.Errorf("win = %.2f, loss = %.2f, want above and below 1500", win.Rating, loss.Rating)
+	}
+	if math.Abs((win.Rating-1500)-(1500-loss.Rating)) > 1e-6 {
+		t.Errorf("win and loss moved by different amounts: %.4f, %.4f", win.Rating-1500, 1500-loss.Rating)
+	}
+}
+
+func TestGlickoWeightDiscountsResult(t *testing.T) {
+	c := DefaultGlicko
+	full := c.Update(c.NewGlicko(1500), []GlickoResult{{Opponent: 1500, OpponentDeviation: 100, Score: 1}})
+	half := c.Update(c.NewGlicko(1500), []GlickoResult{{Opponent: 1500, OpponentDeviation: 100, Score: 1, Weight: 0.5}})
+	unset := c.Update(c.NewGlicko(1500), []GlickoResult{{Opponent: 1500, OpponentDeviation: 100, Score: 1, Weight: 1}})
+
+	if half.Rating <= 1500 || half.Rating >= full.Rating {
+		t.Errorf("half-weight win = %.2f, want between 1500 and %.2f", half.Rating, full.Rating)
+	}
+	if half.Deviation <= full.Deviation {
+		t.Errorf("half-weight deviation = %.2f, want above full-weight %.2f", half.Deviation, full.Deviation)
+	}
+	if unset != full {
+		t.Errorf("weight 1 = %+v, want the same as unset %+v", unset, full)
+	}
+}
@@ -0,0 +1,183 @@
+package rating
+
+import (
+	"fmt"
+	"math"
+)
+
+// Tiers はティア（低い順）
+var Tiers = []string{
+	"IRON", "BRONZE", "SILVER", "GOLD", "PLATINUM", "EMERALD", "DIAMOND",
+	"MASTER", "GRANDMASTER", "CHALLENGER",
+}
+
+// Divisions はディビジョン（低い順）
+var Divisions = []string{"IV", "III", "II", "I"}
+
+// apexTiers はディビジョンがなく、LPが1本のラダーとして続くティア
+var apexTiers = map[string]bool{"MASTER": true, "GRANDMASTER": true, "CHALLENGER": true}
+
+// Scale はティア・ディビジョン・LPとレーティングの対応
+type Scale struct {
+	// DivisionStep は1ディビジョン（100LP）分のレーティング。1ティアは4ディビジョン
+	DivisionStep int `json:"divisionStep"`
+	// ApexSpan はマスター以上のLPを圧縮して割り当てるレーティングの幅
+	ApexSpan int `json:"apexSpan"`
+	// ApexHalfLP はApexSpanの半分に達するマスター以上のLP
+	ApexHalfLP int `json:"apexHalfLp"`
+	// Unranked はランク情報がない場合のレーティング
+	Unranked int `json:"unranked"`
+	// GrandmasterLP と ChallengerLP は逆変換でティアを決めるLPの目安
+	GrandmasterLP int `json:"grandmasterLp"`
+	ChallengerLP  int `json:"challengerLp"`
+}
+
+// DefaultScale はデフォルトの対応（アイアンIV 0LP = 0、マスター 0LP = 2800、上限 4000）
+// サーバーは起動時に環境変数RATING_SCALEで上書きできる
+var DefaultScale = Scale{
+	DivisionStep:  100,
+	ApexSpan:      1200,
+	ApexHalfLP:    600,
+	Unranked:      800,
+	GrandmasterLP: 200,
+	ChallengerLP:  500,
+}
+
+// Rank はティア・ディビジョン・LP
+type Rank struct {
+	Tier string `json:"tier"`
+	Rank string `json:"rank"` // ディビジョン（マスター以上は"I"）
+	LP   int    `json:"lp"`
+}
+
+// Step はティア・ディビジョンの区切りとそのレーティング
+type Step struct {
+	Tier   string `json:"tier"`
+	Rank   string `json:"rank"`
+	LP     int    `json:"lp"`
+	Rating int    `json:"rating"`
+}
+
+// Validate は対応が正しい（レーティングが単調に増え、逆変換できる）かチェックする
+func (s Scale) Validate() error {
+	if s.DivisionStep <= 0 || s.ApexSpan <= 0 || s.ApexHalfLP <= 0 {
+		return fmt.Errorf("divisionStep・apexSpan・apexHalfLpは正の値で指定してください")
+	}
+	if s.Unranked < 0 {
+		return fmt.Errorf("unrankedは0以上で指定してください: %d", s.Unranked)
+	}
+	if s.GrandmasterLP < 0 || s.ChallengerLP <= s.GrandmasterLP {
+		return fmt.Errorf("challengerLpはgrandmasterLpより大きく指定してください: %d, %d", s.GrandmasterLP, s.ChallengerLP)
+	}
+	return nil
+}
+
+// Steps は全てのティア・ディビジョンの区切り（0LP、マスター以上はティアの目安のLP）を低い順に返す
+// レーティング以下で最も高い区切りがToTierのティア・ディビジョンになる
+func (s Scale) Steps() []Step {
+	var steps []Step
+	for _, tier := range Tiers {
+		if apexTiers[tier] {
+			continue
+		}
+		for _, division := range Divisions {
+			steps = append(steps, Step{Tier: tier, Rank: division, Rating: s.FromTier(tier, division, 0)})
+		}
+	}
+	for _, apex := range []Rank{
+		{Tier: "MASTER", Rank: "I", LP: 0},
+		{Tier: "GRANDMASTER", Rank: "I", LP: s.GrandmasterLP},
+		{Tier: "CHALLENGER", Rank: "I", LP: s.ChallengerLP},
+	} {
+		steps = append(steps, Step{Tier: apex.Tier, Rank: apex.Rank, LP: apex.LP, Rating: s.FromTier(apex.Tier, apex.Rank, apex.LP)})
+	}
+	return steps
+}
+
+// FromTier はデフォルトの対応でティア情報をレーティングに変換する
+func FromTier(tier, division string, lp int) int {
+	return DefaultScale.FromTier(tier, division, lp)
+}
+
+// ToTier はデフォルトの対応でレーティングをティア情報に変換する
+func ToTier(rating int) Rank {
+	return DefaultScale.ToTier(rating)
+}
+
+// ApexBase はマスター 0LPのレーティング
+func (s Scale) ApexBase() int {
+	return tierIndex("MASTER") * 4 * s.DivisionStep
+}
+
+// Max はレーティングの上限（これ以上のLPでも到達しない）
+func (s Scale) Max() int {
+	return s.ApexBase() + s.ApexSpan
+}
+
+// FromTier はティア情報をレーティングに変換する
+// マスター以上はティアに関係なくLPだけで決まり、LPが増えるほど上がり幅が小さくなる
+// （上限Maxに漸近する）。不明なティアやUNRANKEDはUnranked
+func (s Scale) FromTier(tier, division string, lp int) int {
+	if apexTiers[tier] {
+		lp = max(lp, 0)
+		return s.ApexBase() + int(math.Round(float64(s.ApexSpan)*float64(lp)/float64(lp+s.ApexHalfLP)))
+	}
+
+	t := tierIndex(tier)
+	if t < 0 {
+		return s.Unranked
+	}
+	d := max(divisionIndex(division), 0)
+	lp = min(max(lp, 0), 100)
+
+	return (t*4+d)*s.DivisionStep + lp*s.DivisionStep/100
+}
+
+// ToTier はレーティングをティア情報に変換する（FromTierの逆変換）
+func (s Scale) ToTier(rating int) Rank {
+	rating = max(rating, 0)
+
+	base := s.ApexBase()
+	if rating < base {
+		step := rating / s.DivisionStep
+		return Rank{
+			Tier: Tiers[step/4],
+			Rank: Divisions[step%4],
+			LP:   (rating - step*s.DivisionStep) * 100 / s.DivisionStep,
+		}
+	}
+
+	// rating = base + span*lp/(lp+half) を lp について解く
+	x := float64(min(rating-base, s.ApexSpan-1))
+	lp := int(math.Round(float64(s.ApexHalfLP) * x / (float64(s.ApexSpan) - x)))
+
+	// ティアはLPの丸めに左右されないよう、目安のLPのレーティングで区切る
+	tier := "MASTER"
+	switch {
+	case rating >= s.FromTier("CHALLENGER", "I", s.ChallengerLP):
+		tier = "CHALLENGER"
+	case rating >= s.FromTier("GRANDMASTER", "I", s.GrandmasterLP):
+		tier = "GRANDMASTER"
+	}
+	return Rank{Tier: tier, Rank: "I", LP: lp}
+}
+
+// tierIndex はティアの順位（アイアンが0）を返す。不明な場合は-1
+func tierIndex(tier string) int {
+	for i, t := range Tiers {
+		if t == tier {
+			return i
+		}
+	}
+	return -1
+}
+
+// divisionIndex はディビジョンの順位（IVが0）を返す。不明な場合は-1
+func divisionIndex(division string) int {
+	for i, d := range Divisions {
+		if d == division {
+			return i
+		}
+	}
+	return -1
+}
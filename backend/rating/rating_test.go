@@ -0,0 +1,167 @@
+package rating
+
+import "testing"
+
+func TestFromTier(t *testing.T) {
+	tests := []struct {
+		tier, division string
+		lp             int
+		want           int
+	}{
+		{"IRON", "IV", 0, 0},
+		{"SILVER", "IV", 0, 800},
+		{"GOLD", "II", 50, 1450},
+		{"DIAMOND", "I", 99, 2799},
+		{"MASTER", "I", 0, 2800},
+		{"GRANDMASTER", "I", 600, 3400},
+		{"UNRANKED", "", 0, 800},
+		{"", "", 0, 800},
+	}
+
+	for _, tt := range tests {
+		if got := FromTier(tt.tier, tt.division, tt.lp); got != tt.want {
+			t.Errorf("FromTier(%q, %q, %d) = %d, want %d", tt.tier, tt.division, tt.lp, got, tt.want)
+		}
+	}
+}
+
+func TestApexCompression(t *testing.T) {
+	s := DefaultScale
+	prev := s.FromTier("MASTER", "I", 0)
+	for lp := 1; lp <= 5000; lp++ {
+		got := s.FromTier("CHALLENGER", "I", lp)
+		if got < prev {
+			t.Fatalf("FromTier(CHALLENGER, %d) = %d, smaller than %d", lp, got, prev)
+		}
+		if got >= s.Max() {
+			t.Fatalf("FromTier(CHALLENGER, %d) = %d, want below cap %d", lp, got, s.Max())
+		}
+		prev = got
+	}
+
+	// マスター以上はティア名ではなくLPで決まる
+	if a, b := s.FromTier("MASTER", "I", 300), s.FromTier("GRANDMASTER", "I", 300); a != b {
+		t.Errorf("MASTER 300LP = %d, GRANDMASTER 300LP = %d, want equal", a, b)
+	}
+}
+
+func TestRoundTripBelowApex(t *testing.T) {
+	s := DefaultScale
+	for _, tier := range Tiers[:tierIndex("MASTER")] {
+		for _, division := range Divisions {
+			for lp := 0; lp < 100; lp++ {
+				rating := s.FromTier(tier, division, lp)
+				got := s.ToTier(rating)
+				if got.Tier != tier || got.Rank != division || got.LP != lp {
+					t.Fatalf("ToTier(FromTier(%s %s %d)) = %+v", tier, division, lp, got)
+				}
+				if back := s.FromTier(got.Tier, got.Rank, got.LP); back != rating {
+					t.Fatalf("FromTier(ToTier(%d)) = %d", rating, back)
+				}
+			}
+		}
+	}
+}
+
+func TestRoundTripApex(t *testing.T) {
+	s := DefaultScale
+	for rating := s.ApexBase(); rating < s.Max(); rating++ {
+		r := s.ToTier(rating)
+		back := s.FromTier(r.Tier, r.Rank, r.LP)
+		if abs(back-rating) > 1 {
+			t.Fatalf("FromTier(ToTier(%d)) = %d (%+v)", rating, back, r)
+		}
+	}
+
+	tests := []struct {
+		lp   int
+		tier string
+	}{
+		{0, "MASTER"},
+		{150, "MASTER"},
+		{250, "GRANDMASTER"},
+		{800, "CHALLENGER"},
+	}
+	for _, tt := range tests {
+		if got := s.ToTier(s.FromTier("MASTER", "I", tt.lp)); got.Tier != tt.tier {
+			t.Errorf("ToTier(%dLP) tier = %s, want %s", tt.lp, got.Tier, tt.tier)
+		}
+	}
+}
+
+func TestCustomScale(t *testing.T) {
+	s := DefaultScale
+	s.DivisionStep = 50
+	s.ApexSpan = 600
+
+	if got := s.FromTier("GOLD", "IV", 50); got != 625 {
+		t.Errorf("FromTier(GOLD IV 50) = %d, want 625", got)
+	}
+	if got := s.Max(); got != 2000 {
+		t.Errorf("Max() = %d, want 2000", got)
+	}
+	if got := s.ToTier(625); got != (Rank{Tier: "GOLD", Rank: "IV", LP: 50}) {
+		t.Errorf("ToTier(625) = %+v", got)
+	}
+}
+
+func TestStepsMatchToTier(t *testing.T) {
+	custom := DefaultScale
+	custom.DivisionStep = 50
+	custom.GrandmasterLP = 300
+
+	for _, s := range []Scale{DefaultScale, custom} {
+		steps := s.Steps()
+		if len(steps) != 7*4+3 {
+			t.Fatalf("steps = %d, want %d", len(steps), 7*4+3)
+		}
+		for i, step := range steps {
+			if i > 0 && step.Rating <= steps[i-1].Rating {
+				t.Errorf("step %+v is not above %+v", step, steps[i-1])
+			}
+			got := s.ToTier(step.Rating)
+			if got.Tier != step.Tier || got.Rank != step.Rank {
+				t.Errorf("ToTier(%d) = %+v, want %s %s", step.Rating, got, step.Tier, step.Rank)
+			}
+			if i > 0 {
+				// 区切りの直前は1つ前のティア・ディビジョン
+				prev := s.ToTier(step.Rating - 1)
+				if prev.Tier != steps[i-1].Tier || prev.Rank != steps[i-1].Rank {
+					t.Errorf("ToTier(%d) = %+v, want %s %s", step.Rating-1, prev, steps[i-1].Tier, steps[i-1].Rank)
+				}
+			}
+		}
+	}
+}
+
+func TestScaleValidate(t *testing.T) {
+	if err := DefaultScale.Validate(); err != nil {
+		t.Fatalf("default scale: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(s *Scale)
+	}{
+		{"zero division step", func(s *Scale) { s.DivisionStep = 0 }},
+		{"zero apex span", func(s *Scale) { s.ApexSpan = 0 }},
+		{"negative unranked", func(s *Scale) { s.Unranked = -1 }},
+		{"challenger below grandmaster", func(s *Scale) { s.ChallengerLP = s.GrandmasterLP }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := DefaultScale
+			tt.modify(&s)
+			if err := s.Validate(); err == nil {
+				t.Errorf("expected an error for %+v", s)
+			}
+		})
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,185 @@
+package riotapi
+
+import (
+	"fmt"
+	"lol-team-backend/rating"
+	"math"
+	"time"
+)
+
+// ARAMのレーティングの出どころ（ARAMRatingResult.Source）
+const (
+	ARAMSourceGames = "aram" // ARAMの試合結果から求めた（ランクは事前分布としてのみ使用）
+	ARAMSourceRank  = "rank" // 集計できるARAMの試合がないため、ランクから求めたベースレーティングのまま
+)
+
+// ARAMRatingResult はARAMのレーティングの計算結果
+// Ratingはランクのベースレーティングを事前分布としてARAMの勝敗でGlicko-2の更新をかけたものに、
+// KDAとダメージの補正を加えた値。ARAMの試合が少ないほどベースレーティングに近く、RDが大きい
+type ARAMRatingResult struct {
+	Rating              int           `json:"rating"`              // 計算されたレーティング
+	Source              string        `json:"source"`              // Ratingの出どころ（"aram"、"rank"）
+	GlickoRating        int           `json:"glickoRating"`        // ARAMの勝敗だけから求めたレーティング（補正前）
+	Deviation           int           `json:"deviation"`           // レーティングの不確かさ（RD、標準偏差に相当）
+	RatingLow           int           `json:"ratingLow"`           // レーティングの95%区間の下限（Rating - 2×RD）
+	RatingHigh          int           `json:"ratingHigh"`          // レーティングの95%区間の上限（Rating + 2×RD）
+	GamesPlayed         int           `json:"gamesPlayed"`         // 分析したARAMのゲーム数
+	WinRate             float64       `json:"winRate"`             // 勝率
+	AverageKDA          float64       `json:"averageKda"`          // 平均KDA
+	AverageDamagePerMin float64       `json:"averageDamagePerMin"` // チャンピオンへの平均ダメージ/min
+	BaseRating          int           `json:"baseRating"`          // ベースレーティング（ランクから、Glickoの初期値）
+	Confidence          float64       `json:"confidence"`          // 信頼度（0-1、RDから）
+	Excluded            ExcludedGames `json:"excluded"`            // 集計から除外したゲーム数（理由別）
+}
+
+// aramStats はARAMの統計情報
+type aramStats struct {
+	wins          int
+	losses        int
+	totalKills    int
+	totalDeaths   int
+	totalAssists  int
+	totalDamage   int
+	totalDuration int // 秒単位
+	outcomes      []gameOutcome
+}
+
+// GetARAMRating は指定されたPUUIDのARAMの試合履歴からレーティングを計算する
+// ARAMの勝敗を1日ごとのレーティング期間としてGlicko-2で更新し、ランクのベースレーティングは
+// 初期値（事前分布）としてだけ使う。ロールがないため、CSの代わりにチャンピオンへのダメージで補正する
+// matchCount: 分析するマッチ数（デフォルト: 20, 最大: 100）
+// blend: ベースレーティングのソロ・フレックスのブレンド方針
+func (c *Client) GetARAMRating(puuid string, matchCount int, blend BlendPolicy) (*ARAMRatingResult, error) {
+	if matchCount <= 0 || matchCount > 100 {
+		matchCount = 20
+	}
+
+	// 1. ベースレーティングを取得（ランク情報から）
+	base, err := c.getBaseRating(puuid, blend)
+	if err != nil {
+		return nil, fmt.Errorf("ベースレーティングの取得に失敗: %w", err)
+	}
+	baseRating := base.Rating
+
+	// 2. ARAMのマッチ履歴を取得
+	matchIDs, err := c.GetMatchIDsByQueue(puuid, QueueARAM, 0, matchCount)
+	if err != nil {
+		return nil, fmt.Errorf("マッチ履歴の取得に失敗: %w", err)
+	}
+
+	// 3. 統計を収集
+	stats := &aramStats{}
+	analyzedMatches := 0
+	var excluded ExcludedGames
+
+	for _, matchID := range matchIDs {
+		match, err := c.GetMatchByID(matchID)
+		if err != nil {
+			excluded.FetchFailed++
+			continue // エラーの場合はスキップ
+		}
+
+		// キューの指定が効かない場合に備えてARAM以外を除外
+		if match.Info.QueueID != QueueARAM {
+			excluded.Queue++
+			continue
+		}
+
+		// リメイクと途中離脱者のいる試合は除外
+		if qualityWeight(match, LeaversExclude, &excluded) == 0 {
+			continue
+		}
+
+		participant := findParticipant(match, puuid)
+		if participant == nil {
+			excluded.NotInMatch++
+			continue
+		}
+
+		analyzedMatches++
+
+		if participant.Win {
+			stats.wins++
+		} else {
+			stats.losses++
+		}
+
+		stats.totalKills += participant.Kills
+		stats.totalDeaths += participant.Deaths
+		stats.totalAssists += participant.Assists
+		stats.totalDamage += participant.TotalDamageDealtToChampions
+		stats.totalDuration += match.Info.GameDuration
+		stats.outcomes = append(stats.outcomes, gameOutcome{
+			end:      gameEndTime(match.Info),
+			win:      participant.Win,
+			weight:   1,
+			opponent: neutralOpponent,
+		})
+	}
+
+	// 4. レーティングを計算
+	if analyzedMatches == 0 {
+		deviation := int(math.Round(rating.DefaultGlicko.InitialDeviation))
+		return &ARAMRatingResult{
+			Rating:       baseRating,
+			Source:       ARAMSourceRank,
+			GlickoRating: baseRating,
+			Deviation:    deviation,
+			RatingLow:    clampRating(baseRating - 2*deviation),
+			RatingHigh:   clampRating(baseRating + 2*deviation),
+			GamesPlayed:  0,
+			BaseRating:   baseRating,
+			Confidence:   0.0,
+			Excluded:     excluded,
+		}, nil
+	}
+
+	g := glickoOutcomes(baseRating, stats.outcomes, time.Now(), 0)
+	confidence := glickoConfidence(g.Deviation)
+	finalRating := calculateARAMRating(g.Rating, stats, confidence)
+	deviation := int(math.Round(g.Deviation))
+
+	return &ARAMRatingResult{
+		Rating:              finalRating,
+		Source:              ARAMSourceGames,
+		GlickoRating:        clampRating(int(math.Round(g.Rating))),
+		Deviation:           deviation,
+		RatingLow:           clampRating(finalRating - 2*deviation),
+		RatingHigh:          clampRating(finalRating + 2*deviation),
+		GamesPlayed:         analyzedMatches,
+		WinRate:             float64(stats.wins) / float64(analyzedMatches) * 100,
+		AverageKDA:          calculateKDA(stats.totalKills, stats.totalDeaths, stats.totalAssists),
+		AverageDamagePerMin: perMinute(stats.totalDamage, stats.totalDuration),
+		BaseRating:          baseRating,
+		Confidence:          confidence,
+		Excluded:            excluded,
+	}, nil
+}
+
+// calculateARAMRating はARAMの勝敗から求めたレーティングにKDAとダメージ効率の補正を加える
+// 勝敗はGlickoの更新で反映済みなので、勝率の補正は加えない
+// confidence: RDから求めた信頼度（試合が少ない場合は補正を抑える）
+func calculateARAMRating(glickoRating float64, stats *aramStats, confidence float64) int {
+	// KDAによる補正（ARAMは集団戦が多くKDAが高めになるため基準を上げる、-100 ~ +100）
+	kda := calculateKDA(stats.totalKills, stats.totalDeaths, stats.totalAssists)
+	kdaAdjustment := 0.0
+	if kda < 2.5 {
+		kdaAdjustment = math.Max((kda-2.5)*50, -100)
+	} else if kda > 3.5 {
+		kdaAdjustment = math.Min((kda-3.5)*50, 100)
+	}
+
+	// ダメージ効率による補正（-50 ~ +50）
+	damagePerMin := perMinute(stats.totalDamage, stats.totalDuration)
+	damageAdjustment := 0.0
+	if damagePerMin < 1200 {
+		damageAdjustment = math.Max((damagePerMin-1200)/10, -50)
+	} else if damagePerMin > 1800 {
+		damageAdjustment = math.Min((damagePerMin-1800)/10, 50)
+	}
+
+	totalAdjustment := (kdaAdjustment + damageAdjustment) * confidence
+
+	// レーティングの範囲を制限（0 ~ レーティングの上限）
+	return clampRating(int(math.Round(glickoRating + totalAdjustment)))
+}
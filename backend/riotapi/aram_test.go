@@ -0,0 +1,61 @@
+package riotapi
+
+import (
+	"lol-team-backend/rating"
+	"testing"
+	"time"
+)
+
+// aramOutcomes は1日1試合ずつの結果を返す（最後の試合がnowの前日）
+func aramOutcomes(now time.Time, wins []bool) []gameOutcome {
+	outcomes := make([]gameOutcome, len(wins))
+	for i, win := range wins {
+		outcomes[i] = gameOutcome{
+			end:      now.AddDate(0, 0, i-len(wins)),
+			win:      win,
+			weight:   1,
+			opponent: neutralOpponent,
+		}
+	}
+	return outcomes
+}
+
+func TestARAMRatingComesFromARAMResults(t *testing.T) {
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	const base = 1500
+
+	repeat := func(win bool, n int) []bool {
+		wins := make([]bool, n)
+		for i := range wins {
+			wins[i] = win
+		}
+		return wins
+	}
+
+	winning := glickoOutcomes(base, aramOutcomes(now, repeat(true, 20)), now, 0)
+	losing := glickoOutcomes(base, aramOutcomes(now, repeat(false, 20)), now, 0)
+	if winning.Rating <= base+100 || losing.Rating >= base-100 {
+		t.Errorf("ARAM results barely moved the rating: winning %.0f, losing %.0f (base %d)", winning.Rating, losing.Rating, base)
+	}
+	if winning.Deviation >= rating.DefaultGlicko.InitialDeviation {
+		t.Errorf("deviation after 20 games = %.0f, want below the prior %.0f", winning.Deviation, rating.DefaultGlicko.InitialDeviation)
+	}
+
+	// 試合が少ないほどランク（事前分布）に近い
+	few := glickoOutcomes(base, aramOutcomes(now, repeat(true, 2)), now, 0)
+	if few.Rating-base >= winning.Rating-base {
+		t.Errorf("2 wins moved the rating (%.0f) as far as 20 wins (%.0f)", few.Rating, winning.Rating)
+	}
+	if few.Deviation <= winning.Deviation {
+		t.Errorf("2 games deviation %.0f, want above 20 games deviation %.0f", few.Deviation, winning.Deviation)
+	}
+
+	// KDAとダメージの補正は信頼度に比例する
+	stats := &aramStats{totalKills: 100, totalDeaths: 10, totalAssists: 100, totalDamage: 3000 * 60, totalDuration: 60}
+	if got := calculateARAMRating(base, stats, 0); got != base {
+		t.Errorf("adjustment with zero confidence = %d, want %d", got, base)
+	}
+	if got := calculateARAMRating(base, stats, 1); got != base+150 {
+		t.Errorf("adjustment with full confidence = %d, want %d", got, base+150)
+	}
+}
@@ -0,0 +1,146 @@
+package riotapi
+
+import (
+	"fmt"
+	"lol-team-backend/rating"
+	"math"
+)
+
+// ランクのキュータイプ
+const (
+	QueueTypeSolo = "RANKED_SOLO_5x5"
+	QueueTypeFlex = "RANKED_FLEX_SR"
+)
+
+// ベースレーティングのブレンド方針
+const (
+	BlendSolo  = "solo"  // ソロランクを優先し、なければ最初のエントリー（従来の動作）
+	BlendGames = "games" // ソロとフレックスを試合数×キューの重みで加重平均する
+	BlendMax   = "max"   // ソロとフレックスのうち高い方
+)
+
+// BlendPolicy はソロランクとフレックスランクからベースレーティングを決める方針
+type BlendPolicy struct {
+	// Mode はブレンド方針（"solo"、"games"、"max"。デフォルト: "games"）
+	Mode string `json:"mode,omitempty"`
+	// SoloWeight はgamesでのソロランク1試合あたりの重み（デフォルト: 1.0）
+	SoloWeight float64 `json:"soloWeight,omitempty"`
+	// FlexWeight はgamesでのフレックスランク1試合あたりの重み（デフォルト: 0.5）
+	FlexWeight float64 `json:"flexWeight,omitempty"`
+}
+
+// QueueContribution はベースレーティングへの各キューの寄与
+type QueueContribution struct {
+	QueueType string  `json:"queueType"` // キュータイプ
+	Tier      string  `json:"tier"`      // ティア
+	Rank      string  `json:"rank"`      // ディビジョン
+	LP        int     `json:"lp"`        // リーグポイント
+	Rating    int     `json:"rating"`    // このキューのランクから求めたレーティング
+	Games     int     `json:"games"`     // このキューの試合数（勝利数 + 敗北数）
+	Weight    float64 `json:"weight"`    // ブレンドでの重み（合計1、寄与しない場合は0）
+}
+
+// BaseRating はブレンドしたベースレーティング
+type BaseRating struct {
+	Rating int                 `json:"rating"` // ベースレーティング
+	Policy string              `json:"policy"` // 使用したブレンド方針
+	Queues []QueueContribution `json:"queues"` // 各キューの寄与
+}
+
+// withDefaults は未指定の項目にデフォルト値を設定した方針を返す
+func (p BlendPolicy) withDefaults() BlendPolicy {
+	if p.Mode == "" {
+		p.Mode = BlendGames
+	}
+	if p.SoloWeight <= 0 {
+		p.SoloWeight = 1.0
+	}
+	if p.FlexWeight <= 0 {
+		p.FlexWeight = 0.5
+	}
+	return p
+}
+
+// Validate はブレンド方針が正しいかチェックする
+func (p BlendPolicy) Validate() error {
+	switch p.Mode {
+	case "", BlendSolo, BlendGames, BlendMax:
+		return nil
+	}
+	return fmt.Errorf("不明なブレンド方針です: %s", p.Mode)
+}
+
+// BlendRating はリーグエントリーから方針に従ってベースレーティングを求める
+// エントリーがない場合はランクなしのレーティングになる
+func BlendRating(entries []LeagueEntry, policy BlendPolicy) *BaseRating {
+	policy = policy.withDefaults()
+	result := &BaseRating{Policy: policy.Mode, Queues: []QueueContribution{}}
+
+	solo, flex := -1, -1
+	for i, entry := range entries {
+		games := entry.Wins + entry.Losses
+		result.Queues = append(result.Queues, QueueContribution{
+			QueueType: entry.QueueType,
+			Tier:      entry.Tier,
+			Rank:      entry.Rank,
+			LP:        entry.LeaguePoints,
+			Rating:    rating.FromTier(entry.Tier, entry.Rank, entry.LeaguePoints),
+			Games:     games,
+		})
+		switch entry.QueueType {
+		case QueueTypeSolo:
+			solo = i
+		case QueueTypeFlex:
+			flex = i
+		}
+	}
+
+	if len(entries) == 0 {
+		result.Rating = rating.DefaultScale.Unranked
+		return result
+	}
+
+	// 寄与するキューの重みを決める（合計が0の場合はsoloと同じ扱い）
+	weights := make([]float64, len(entries))
+	switch policy.Mode {
+	case BlendGames:
+		if solo >= 0 {
+			weights[solo] = policy.SoloWeight * float64(result.Queues[solo].Games)
+		}
+		if flex >= 0 {
+			weights[flex] = policy.FlexWeight * float64(result.Queues[flex].Games)
+		}
+	case BlendMax:
+		best := -1
+		for _, i := range []int{solo, flex} {
+			if i >= 0 && (best < 0 || result.Queues[i].Rating > result.Queues[best].Rating) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			weights[best] = 1
+		}
+	}
+
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	if total == 0 {
+		if solo >= 0 {
+			weights[solo] = 1
+		} else {
+			weights[0] = 1
+		}
+		total = 1
+	}
+
+	blended := 0.0
+	for i := range result.Queues {
+		result.Queues[i].Weight = weights[i] / total
+		blended += result.Queues[i].Weight * float64(result.Queues[i].Rating)
+	}
+	result.Rating = int(math.Round(blended))
+
+	return result
+}
@@ -0,0 +1,91 @@
+package riotapi
+
+import (
+	"lol-team-backend/rating"
+	"math"
+	"testing"
+)
+
+func TestBlendRating(t *testing.T) {
+	solo := LeagueEntry{QueueType: QueueTypeSolo, Tier: "GOLD", Rank: "II", LeaguePoints: 50, Wins: 60, Losses: 40}
+	flex := LeagueEntry{QueueType: QueueTypeFlex, Tier: "PLATINUM", Rank: "IV", LeaguePoints: 0, Wins: 50, Losses: 50}
+	soloRating := rating.FromTier(solo.Tier, solo.Rank, solo.LeaguePoints)
+	flexRating := rating.FromTier(flex.Tier, flex.Rank, flex.LeaguePoints)
+
+	noGames := func(e LeagueEntry) LeagueEntry {
+		e.Wins, e.Losses = 0, 0
+		return e
+	}
+
+	tests := []struct {
+		name    string
+		entries []LeagueEntry
+		policy  BlendPolicy
+		want    int
+		mode    string
+	}{
+		{"unranked", nil, BlendPolicy{}, rating.DefaultScale.Unranked, BlendGames},
+		{"solo only", []LeagueEntry{solo}, BlendPolicy{}, soloRating, BlendGames},
+		{"flex only", []LeagueEntry{flex}, BlendPolicy{}, flexRating, BlendGames},
+		{
+			// デフォルトの重み: ソロ100試合×1.0、フレックス100試合×0.5
+			name:    "games weighs solo over flex",
+			entries: []LeagueEntry{flex, solo},
+			policy:  BlendPolicy{},
+			want:    int(math.Round(float64(2*soloRating+flexRating) / 3)),
+			mode:    BlendGames,
+		},
+		{
+			name:    "games with custom weights",
+			entries: []LeagueEntry{solo, flex},
+			policy:  BlendPolicy{Mode: BlendGames, SoloWeight: 1, FlexWeight: 1},
+			want:    int(math.Round(float64(soloRating+flexRating) / 2)),
+			mode:    BlendGames,
+		},
+		{
+			name:    "games without games falls back to solo",
+			entries: []LeagueEntry{noGames(flex), noGames(solo)},
+			policy:  BlendPolicy{Mode: BlendGames},
+			want:    soloRating,
+			mode:    BlendGames,
+		},
+		{"solo prefers solo", []LeagueEntry{flex, solo}, BlendPolicy{Mode: BlendSolo}, soloRating, BlendSolo},
+		{"solo falls back to first entry", []LeagueEntry{flex}, BlendPolicy{Mode: BlendSolo}, flexRating, BlendSolo},
+		{"max takes the higher queue", []LeagueEntry{solo, flex}, BlendPolicy{Mode: BlendMax}, max(soloRating, flexRating), BlendMax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BlendRating(tt.entries, tt.policy)
+			if got.Rating != tt.want {
+				t.Errorf("rating = %d, want %d", got.Rating, tt.want)
+			}
+			if got.Policy != tt.mode {
+				t.Errorf("policy = %s, want %s", got.Policy, tt.mode)
+			}
+			if len(got.Queues) != len(tt.entries) {
+				t.Fatalf("queues = %d, want %d", len(got.Queues), len(tt.entries))
+			}
+			if len(tt.entries) > 0 {
+				total := 0.0
+				for _, q := range got.Queues {
+					total += q.Weight
+				}
+				if math.Abs(total-1) > 1e-9 {
+					t.Errorf("weights sum to %v, want 1", total)
+				}
+			}
+		})
+	}
+}
+
+func TestBlendPolicyValidate(t *testing.T) {
+	for _, mode := range []string{"", BlendSolo, BlendGames, BlendMax} {
+		if err := (BlendPolicy{Mode: mode}).Validate(); err != nil {
+			t.Errorf("mode %q: %v", mode, err)
+		}
+	}
+	if err := (BlendPolicy{Mode: "average"}).Validate(); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
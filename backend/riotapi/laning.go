@@ -0,0 +1,244 @@
+package riotapi
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// レーン戦の比較時点（分）
+const (
+	laningEarlyMinute = 10
+	laningLateMinute  = 15
+)
+
+// LaningOptions はレーン戦の分析のオプション
+type LaningOptions struct {
+	// Role は分析するロール（空の場合は全ロール）
+	Role string `json:"role,omitempty"`
+	// Queues は分析するキューID（デフォルト: DefaultRoleQueues）
+	Queues []int `json:"queues,omitempty"`
+}
+
+// LaningGame は1試合のレーン戦の指標
+type LaningGame struct {
+	MatchID          string `json:"matchId"`          // マッチID
+	Role             string `json:"role"`             // ロール
+	Champion         string `json:"champion"`         // 使用チャンピオン
+	OpponentChampion string `json:"opponentChampion"` // 対面のチャンピオン（いない場合は空）
+	CSAt10           int    `json:"csAt10"`           // 10分時点のCS
+	GoldDiffAt10     int    `json:"goldDiffAt10"`     // 10分時点の対面とのゴールド差
+	XPDiffAt10       int    `json:"xpDiffAt10"`       // 10分時点の対面との経験値差
+	GoldDiffAt15     int    `json:"goldDiffAt15"`     // 15分時点の対面とのゴールド差
+	XPDiffAt15       int    `json:"xpDiffAt15"`       // 15分時点の対面との経験値差
+	Reached15        bool   `json:"reached15"`        // 試合が15分まで続いたか
+	FirstTower       bool   `json:"firstTower"`       // ファーストタワーに関与したか（破壊またはアシスト）
+	Win              bool   `json:"win"`              // 試合に勝ったか
+}
+
+// LaningResult はレーン戦の分析結果
+type LaningResult struct {
+	Role                string        `json:"role"`                // 分析したロール（空は全ロール）
+	GamesAnalyzed       int           `json:"gamesAnalyzed"`       // 分析したゲーム数
+	AverageCSAt10       float64       `json:"averageCsAt10"`       // 平均CS@10
+	AverageGoldDiffAt10 float64       `json:"averageGoldDiffAt10"` // 平均ゴールド差@10
+	AverageXPDiffAt10   float64       `json:"averageXpDiffAt10"`   // 平均経験値差@10
+	AverageGoldDiffAt15 float64       `json:"averageGoldDiffAt15"` // 平均ゴールド差@15（15分まで続いた試合のみ）
+	AverageXPDiffAt15   float64       `json:"averageXpDiffAt15"`   // 平均経験値差@15（15分まで続いた試合のみ）
+	LaneWinRate         float64       `json:"laneWinRate"`         // ゴールド差@15（なければ@10）が正だった割合（%）
+	FirstTowerRate      float64       `json:"firstTowerRate"`      // ファーストタワーに関与した割合（%）
+	Games               []LaningGame  `json:"games"`               // 試合ごとの指標（新しい順）
+	Excluded            ExcludedGames `json:"excluded"`            // 分析から除外したゲーム数（理由別）
+}
+
+// GetLaningStats は直近の試合のタイムラインから、対面と比べたレーン戦の指標を計算する
+// puuid: プレイヤーのPUUID
+// matchCount: 分析するマッチ数（デフォルト: 20, 最大: 100）
+func (c *Client) GetLaningStats(puuid string, matchCount int, opts LaningOptions) (*LaningResult, error) {
+	if matchCount <= 0 || matchCount > 100 {
+		matchCount = 20
+	}
+
+	queues := opts.Queues
+	if len(queues) == 0 {
+		queues = DefaultRoleQueues
+	}
+	allowedQueues := make(map[int]bool, len(queues))
+	for _, queue := range queues {
+		allowedQueues[queue] = true
+	}
+
+	matchIDs, err := c.GetMatchIDsByQueues(puuid, queues, matchCount)
+	if err != nil {
+		return nil, fmt.Errorf("マッチ履歴の取得に失敗: %w", err)
+	}
+
+	result := &LaningResult{Role: opts.Role, Games: []LaningGame{}}
+
+	for _, matchID := range matchIDs {
+		match, err := c.GetMatchByID(matchID)
+		if err != nil {
+			result.Excluded.FetchFailed++
+			continue
+		}
+
+		if !allowedQueues[match.Info.QueueID] {
+			result.Excluded.Queue++
+			continue
+		}
+		if match.Info.MapID != MapSummonersRift {
+			result.Excluded.Map++
+			continue
+		}
+
+		// リメイクと途中離脱者のいる試合はレーン戦が成立していないので常に除外する
+		if qualityWeight(match, LeaversExclude, &result.Excluded) == 0 {
+			continue
+		}
+
+		participant := findParticipant(match, puuid)
+		if participant == nil {
+			result.Excluded.NotInMatch++
+			continue
+		}
+
+		role := normalizeRole(participant.TeamPosition)
+		if _, ok := roleProfiles[role]; !ok {
+			result.Excluded.NoRole++
+			continue
+		}
+		if opts.Role != "" && role != opts.Role {
+			continue // 指定されたロール以外はスキップ
+		}
+
+		timeline, err := c.GetMatchTimelineByID(matchID)
+		if err != nil {
+			result.Excluded.FetchFailed++
+			continue
+		}
+
+		game, ok := analyzeLaning(match, timeline, participant)
+		if !ok {
+			result.Excluded.FetchFailed++
+			continue
+		}
+		game.MatchID = matchID
+		result.Games = append(result.Games, game)
+	}
+
+	result.summarize()
+	return result, nil
+}
+
+// summarize は試合ごとの指標から平均と割合を計算する
+func (r *LaningResult) summarize() {
+	r.GamesAnalyzed = len(r.Games)
+	if r.GamesAnalyzed == 0 {
+		return
+	}
+
+	reached15 := 0
+	laneWins := 0
+	firstTowers := 0
+	for _, g := range r.Games {
+		r.AverageCSAt10 += float64(g.CSAt10)
+		r.AverageGoldDiffAt10 += float64(g.GoldDiffAt10)
+		r.AverageXPDiffAt10 += float64(g.XPDiffAt10)
+		if g.Reached15 {
+			reached15++
+			r.AverageGoldDiffAt15 += float64(g.GoldDiffAt15)
+			r.AverageXPDiffAt15 += float64(g.XPDiffAt15)
+		}
+		if g.laneGoldDiff() > 0 {
+			laneWins++
+		}
+		if g.FirstTower {
+			firstTowers++
+		}
+	}
+
+	n := float64(r.GamesAnalyzed)
+	r.AverageCSAt10 /= n
+	r.AverageGoldDiffAt10 /= n
+	r.AverageXPDiffAt10 /= n
+	if reached15 > 0 {
+		r.AverageGoldDiffAt15 /= float64(reached15)
+		r.AverageXPDiffAt15 /= float64(reached15)
+	}
+	r.LaneWinRate = float64(laneWins) / n * 100
+	r.FirstTowerRate = float64(firstTowers) / n * 100
+}
+
+// laneGoldDiff はレーン戦の結果とみなすゴールド差（15分まで続いた試合は@15、それ以外は@10）
+func (g LaningGame) laneGoldDiff() int {
+	if g.Reached15 {
+		return g.GoldDiffAt15
+	}
+	return g.GoldDiffAt10
+}
+
+// analyzeLaning は試合とタイムラインから、プレイヤーの対面と比べたレーン戦の指標を求める
+// 10分時点のフレームがない（試合が短すぎる等）場合はfalse
+func analyzeLaning(match *Match, timeline *MatchTimeline, p *Participant) (LaningGame, bool) {
+	role := normalizeRole(p.TeamPosition)
+	game := LaningGame{
+		Role:       role,
+		Champion:   p.ChampionName,
+		FirstTower: p.FirstTowerKill || p.FirstTowerAssist,
+		Win:        p.Win,
+	}
+
+	opponent := findOpponent(match, p)
+	if opponent != nil {
+		game.OpponentChampion = opponent.ChampionName
+	}
+
+	early, ok := frameAt(timeline, laningEarlyMinute)
+	if !ok {
+		return game, false
+	}
+	self := early.ParticipantFrames[strconv.Itoa(p.ParticipantID)]
+	game.CSAt10 = self.MinionsKilled + self.JungleMinionsKilled
+	if opponent != nil {
+		other := early.ParticipantFrames[strconv.Itoa(opponent.ParticipantID)]
+		game.GoldDiffAt10 = self.TotalGold - other.TotalGold
+		game.XPDiffAt10 = self.XP - other.XP
+	}
+
+	if late, ok := frameAt(timeline, laningLateMinute); ok {
+		game.Reached15 = true
+		if opponent != nil {
+			self := late.ParticipantFrames[strconv.Itoa(p.ParticipantID)]
+			other := late.ParticipantFrames[strconv.Itoa(opponent.ParticipantID)]
+			game.GoldDiffAt15 = self.TotalGold - other.TotalGold
+			game.XPDiffAt15 = self.XP - other.XP
+		}
+	}
+
+	return game, true
+}
+
+// frameAt は指定した分の時点のフレームを返す（その時点まで試合が続いていない場合はfalse）
+func frameAt(timeline *MatchTimeline, minute int) (*TimelineFrame, bool) {
+	target := minute * 60 * 1000
+	for i := range timeline.Info.Frames {
+		frame := &timeline.Info.Frames[i]
+		if frame.Timestamp >= target {
+			// 最後のフレームは試合終了時点なので、目標時刻から大きく離れている場合は使わない
+			if frame.Timestamp-target > 60*1000 {
+				return nil, false
+			}
+			return frame, true
+		}
+	}
+	return nil, false
+}
+
+// findParticipant は試合からPUUIDのプレイヤーを探す
+func findParticipant(match *Match, puuid string) *Participant {
+	for i := range match.Info.Participants {
+		if match.Info.Participants[i].PUUID == puuid {
+			return &match.Info.Participants[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package riotapi
+
+import (
+	"math"
+	"testing"
+)
+
+// laningMatch はMIDの対面同士（参加者1と2）の試合を返す
+func laningMatch(withOpponent bool) *Match {
+	match := &Match{}
+	match.Info.Participants = []Participant{
+		{ParticipantID: 1, TeamID: 100, TeamPosition: "MIDDLE", ChampionName: "Ahri", FirstTowerAssist: true, Win: true},
+		{ParticipantID: 3, TeamID: 200, TeamPosition: "TOP", ChampionName: "Garen"},
+	}
+	if withOpponent {
+		match.Info.Participants = append(match.Info.Participants,
+			Participant{ParticipantID: 2, TeamID: 200, TeamPosition: "MIDDLE", ChampionName: "Zed"})
+	}
+	return match
+}
+
+// laningFrame は参加者1と2の指定時刻のフレームを返す
+func laningFrame(timestamp, cs, gold1, gold2, xp1, xp2 int) TimelineFrame {
+	return TimelineFrame{
+		Timestamp: timestamp,
+		ParticipantFrames: map[string]ParticipantFrame{
+			"1": {MinionsKilled: cs - 4, JungleMinionsKilled: 4, TotalGold: gold1, XP: xp1},
+			"2": {MinionsKilled: 70, TotalGold: gold2, XP: xp2},
+			"3": {MinionsKilled: 90, TotalGold: 9999, XP: 9999},
+		},
+	}
+}
+
+func TestAnalyzeLaning(t *testing.T) {
+	start := laningFrame(0, 0, 500, 500, 0, 0)
+	at10 := laningFrame(600_120, 82, 4000, 3600, 5000, 5200)
+	at15 := laningFrame(900_050, 130, 6500, 5700, 8000, 7900)
+
+	tests := []struct {
+		name         string
+		withOpponent bool
+		frames       []TimelineFrame
+		ok           bool
+		want         LaningGame
+	}{
+		{
+			"10 and 15 minutes", true,
+			[]TimelineFrame{start, at10, at15},
+			true,
+			LaningGame{CSAt10: 82, GoldDiffAt10: 400, XPDiffAt10: -200, GoldDiffAt15: 800, XPDiffAt15: 100, Reached15: true},
+		},
+		{
+			"ended before 15 minutes", true,
+			[]TimelineFrame{start, at10, laningFrame(780_000, 100, 5000, 5000, 0, 0)},
+			true,
+			LaningGame{CSAt10: 82, GoldDiffAt10: 400, XPDiffAt10: -200},
+		},
+		{
+			"final frame long after 15 minutes is not used", true,
+			[]TimelineFrame{start, at10, laningFrame(1_000_000, 160, 9000, 5000, 0, 0)},
+			true,
+			LaningGame{CSAt10: 82, GoldDiffAt10: 400, XPDiffAt10: -200},
+		},
+		{
+			"no lane opponent", false,
+			[]TimelineFrame{start, at10, at15},
+			true,
+			LaningGame{CSAt10: 82, Reached15: true},
+		},
+		{
+			"too short for 10 minutes", true,
+			[]TimelineFrame{start, laningFrame(480_000, 60, 3000, 3000, 0, 0)},
+			false,
+			LaningGame{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := laningMatch(tt.withOpponent)
+			timeline := &MatchTimeline{Info: TimelineInfo{Frames: tt.frames}}
+			got, ok := analyzeLaning(match, timeline, &match.Info.Participants[0])
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+
+			if got.Role != "MID" || got.Champion != "Ahri" || !got.FirstTower || !got.Win {
+				t.Errorf("game = %+v, want MID Ahri with first tower and a win", got)
+			}
+			wantOpponent := ""
+			if tt.withOpponent {
+				wantOpponent = "Zed"
+			}
+			if got.OpponentChampion != wantOpponent {
+				t.Errorf("OpponentChampion = %q, want %q", got.OpponentChampion, wantOpponent)
+			}
+
+			got.Role, got.Champion, got.OpponentChampion, got.FirstTower, got.Win = "", "", "", false, false
+			if got != tt.want {
+				t.Errorf("analyzeLaning = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLaningSummarize(t *testing.T) {
+	r := &LaningResult{Games: []LaningGame{
+		{CSAt10: 80, GoldDiffAt10: 300, GoldDiffAt15: -100, Reached15: true, FirstTower: true},
+		{CSAt10: 70, GoldDiffAt10: -200, GoldDiffAt15: 500, Reached15: true},
+		{CSAt10: 60, GoldDiffAt10: 100},
+		{CSAt10: 90, GoldDiffAt10: -600},
+	}}
+	r.summarize()
+
+	if r.GamesAnalyzed != 4 {
+		t.Errorf("GamesAnalyzed = %d, want 4", r.GamesAnalyzed)
+	}
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"AverageCSAt10", r.AverageCSAt10, 75},
+		{"AverageGoldDiffAt10", r.AverageGoldDiffAt10, -100},
+		{"AverageGoldDiffAt15", r.AverageGoldDiffAt15, 200}, // 15分まで続いた2試合のみ
+		{"LaneWinRate", r.LaneWinRate, 50},                  // @15が正の1試合と、@10が正の1試合
+		{"FirstTowerRate", r.FirstTowerRate, 25},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
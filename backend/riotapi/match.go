@@ -1,6 +1,11 @@
 package riotapi
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // puuidでマッチIDのリストを取得する
 // GET /lol/rso-match/v1/matches/ids
@@ -40,3 +45,77 @@ func (c *Client) GetMatchTimelineByID(matchID string) (*MatchTimeline, error) {
 	}
 	return &timeline, nil
 }
+
+// puuidとキューIDでマッチIDのリストを取得する
+// GET /lol/rso-match/v1/matches/ids
+// queue: Queue ID (e.g. 450 for ARAM)
+func (c *Client) GetMatchIDsByQueue(puuid string, queue, start, count int) ([]string, error) {
+	return c.GetMatchIDsFiltered(puuid, MatchIDFilter{Queue: queue}, start, count)
+}
+
+// puuidと複数のキューIDでマッチIDのリストを取得する
+// GET /lol/rso-match/v1/matches/ids（キューごと）
+// キューごとにqueueで絞り込んで取得し、新しい順に合わせて最大count件返す
+func (c *Client) GetMatchIDsByQueues(puuid string, queues []int, count int) ([]string, error) {
+	if len(queues) == 1 {
+		return c.GetMatchIDsByQueue(puuid, queues[0], 0, count)
+	}
+
+	lists := make([][]string, 0, len(queues))
+	for _, queue := range queues {
+		matchIDs, err := c.GetMatchIDsByQueue(puuid, queue, 0, count)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, matchIDs)
+	}
+	return mergeMatchIDs(lists, count), nil
+}
+
+// mergeMatchIDs はキューごとのマッチIDを重複を除いて新しい順に並べ、最大count件返す
+// マッチIDの番号（"JP1_123456789"の"_"以降）は試合の新しいものほど大きい
+func mergeMatchIDs(lists [][]string, count int) []string {
+	seen := make(map[string]bool)
+	merged := []string{}
+	for _, matchIDs := range lists {
+		for _, id := range matchIDs {
+			if !seen[id] {
+				seen[id] = true
+				merged = append(merged, id)
+			}
+		}
+	}
+
+	number := func(id string) int64 {
+		n, err := strconv.ParseInt(id[strings.LastIndex(id, "_")+1:], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return n
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return number(merged[i]) > number(merged[j]) })
+
+	if len(merged) > count {
+		merged = merged[:count]
+	}
+	return merged
+}
+
+// puuidと絞り込み条件でマッチIDのリストを取得する
+// GET /lol/rso-match/v1/matches/ids
+// filter: Queue ID and/or match type ("ranked", "normal", "tourney", "tutorial")
+func (c *Client) GetMatchIDsFiltered(puuid string, filter MatchIDFilter, start, count int) ([]string, error) {
+	endpoint := fmt.Sprintf("/lol/rso-match/v1/matches/ids?puuid=%s&start=%d&count=%d", puuid, start, count)
+	if filter.Queue > 0 {
+		endpoint += fmt.Sprintf("&queue=%d", filter.Queue)
+	}
+	if filter.Type != "" {
+		endpoint += "&type=" + filter.Type
+	}
+	var matchIDs []string
+	err := c.makeRequest(endpoint, &matchIDs, true)
+	if err != nil {
+		return nil, err
+	}
+	return matchIDs, nil
+}
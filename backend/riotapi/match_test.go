@@ -0,0 +1,58 @@
+package riotapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeMatchIDs(t *testing.T) {
+	tests := []struct {
+		name  string
+		lists [][]string
+		count int
+		want  []string
+	}{
+		{
+			name: "interleaves queues by recency",
+			lists: [][]string{
+				{"JP1_500", "JP1_300", "JP1_100"}, // ランク ソロ
+				{"JP1_400", "JP1_200"},            // ランク フレックス
+				{"JP1_450"},                       // ノーマル
+			},
+			count: 10,
+			want:  []string{"JP1_500", "JP1_450", "JP1_400", "JP1_300", "JP1_200", "JP1_100"},
+		},
+		{
+			name:  "keeps the newest count",
+			lists: [][]string{{"JP1_500", "JP1_100"}, {"JP1_400", "JP1_300"}},
+			count: 3,
+			want:  []string{"JP1_500", "JP1_400", "JP1_300"},
+		},
+		{
+			name:  "drops duplicates",
+			lists: [][]string{{"JP1_300", "JP1_200"}, {"JP1_300"}},
+			count: 10,
+			want:  []string{"JP1_300", "JP1_200"},
+		},
+		{
+			name:  "compares numbers, not strings",
+			lists: [][]string{{"JP1_99"}, {"JP1_100"}},
+			count: 10,
+			want:  []string{"JP1_100", "JP1_99"},
+		},
+		{
+			name:  "empty",
+			lists: [][]string{{}, nil},
+			count: 10,
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeMatchIDs(tt.lists, tt.count); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeMatchIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
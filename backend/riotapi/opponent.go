@@ -0,0 +1,75 @@
+package riotapi
+
+import (
+	"lol-team-backend/rating"
+	"math"
+)
+
+// 対面の強さによる補正の係数（期待勝率はGlickoの更新と同じrating.DefaultGlicko.WinScaleで求める）
+const (
+	// opponentWeightScale は対面がこの値だけ強い（弱い）ときに試合の重みを2倍（半分）にする
+	opponentWeightScale = 800.0
+	// opponentWeightLimit は試合の重みの上限（下限はその逆数）
+	opponentWeightLimit = 2.0
+)
+
+// opponentStrength は1試合の対面の強さ
+type opponentStrength struct {
+	rated    bool    // 対面のランクが分かったか
+	rating   int     // 対面のレーティング
+	weight   float64 // 試合の重みの倍率（強い対面ほど大きい）
+	expected float64 // 対面とのレーティング差から見た期待勝率
+}
+
+// neutralOpponent は対面の強さが分からない試合（補正なし）
+var neutralOpponent = opponentStrength{weight: 1, expected: 0.5}
+
+// opponentRatings は1回の集計の中で取得した対面のレーティング（同じ相手の再取得を避ける）
+type opponentRatings map[string]*int
+
+// newOpponentStrength は対面のレーティングとプレイヤーのベースレーティングから補正を求める
+func newOpponentStrength(opponent, base int) opponentStrength {
+	diff := float64(opponent - base)
+	return opponentStrength{
+		rated:    true,
+		rating:   opponent,
+		weight:   math.Min(math.Max(math.Pow(2, diff/opponentWeightScale), 1/opponentWeightLimit), opponentWeightLimit),
+		expected: 1 / (1 + math.Pow(10, diff/rating.DefaultGlicko.WinScale)),
+	}
+}
+
+// opponentStrength は試合の対面（相手チームの同じロール）のランクを調べ、補正を返す
+// ランクのない対面や取得に失敗した場合は補正なし。リーグエントリーはクライアントのキャッシュと
+// ratingsに残るので、同じ相手は再取得しない
+func (c *Client) opponentStrength(match *Match, p *Participant, base int, policy BlendPolicy, ratings opponentRatings) opponentStrength {
+	opponent := findOpponent(match, p)
+	if opponent == nil || opponent.PUUID == "" {
+		return neutralOpponent
+	}
+
+	rating, ok := ratings[opponent.PUUID]
+	if !ok {
+		entries, err := c.GetLeagueEntriesByPUUID(opponent.PUUID)
+		if err == nil && len(entries) > 0 {
+			r := BlendRating(entries, policy).Rating
+			rating = &r
+		}
+		ratings[opponent.PUUID] = rating
+	}
+	if rating == nil {
+		return neutralOpponent
+	}
+	return newOpponentStrength(*rating, base)
+}
+
+// findOpponent は相手チームで同じロールのプレイヤー（対面）を探す
+func findOpponent(match *Match, p *Participant) *Participant {
+	role := normalizeRole(p.TeamPosition)
+	for i := range match.Info.Participants {
+		other := &match.Info.Participants[i]
+		if other.TeamID != p.TeamID && normalizeRole(other.TeamPosition) == role {
+			return other
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package riotapi
+
+import (
+	"lol-team-backend/rating"
+	"math"
+	"testing"
+)
+
+func TestNewOpponentStrength(t *testing.T) {
+	scale := rating.DefaultGlicko.WinScale
+	tests := []struct {
+		name     string
+		opponent int
+		weight   float64
+		expected float64
+	}{
+		{"even", 1500, 1, 0.5},
+		{"stronger", 1500 + opponentWeightScale, 2, 1 / (1 + math.Pow(10, opponentWeightScale/scale))},
+		{"weaker", 1500 - opponentWeightScale, 0.5, 1 / (1 + math.Pow(10, -opponentWeightScale/scale))},
+		{"much stronger is capped", 1500 + 3*opponentWeightScale, opponentWeightLimit, 1 / (1 + math.Pow(10, 3*opponentWeightScale/scale))},
+		{"much weaker is capped", 1500 - 3*opponentWeightScale, 1 / opponentWeightLimit, 1 / (1 + math.Pow(10, -3*opponentWeightScale/scale))},
+		{"one win scale weaker", 1500 - int(scale), 1 / opponentWeightLimit, 10.0 / 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newOpponentStrength(tt.opponent, 1500)
+			if !got.rated || got.rating != tt.opponent {
+				t.Errorf("strength = %+v, want rated %d", got, tt.opponent)
+			}
+			if math.Abs(got.weight-tt.weight) > 1e-9 {
+				t.Errorf("weight = %f, want %f", got.weight, tt.weight)
+			}
+			if math.Abs(got.expected-tt.expected) > 1e-9 {
+				t.Errorf("expected = %f, want %f", got.expected, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindOpponent(t *testing.T) {
+	match := &Match{}
+	match.Info.Participants = []Participant{
+		{PUUID: "self", TeamID: 100, TeamPosition: "BOTTOM"},
+		{PUUID: "ally", TeamID: 100, TeamPosition: "UTILITY"},
+		{PUUID: "support", TeamID: 200, TeamPosition: "UTILITY"},
+		{PUUID: "adc", TeamID: 200, TeamPosition: "BOTTOM"},
+	}
+
+	if got := findOpponent(match, &match.Info.Participants[0]); got == nil || got.PUUID != "adc" {
+		t.Errorf("opponent of the ADC = %+v, want adc", got)
+	}
+	if got := findOpponent(match, &match.Info.Participants[1]); got == nil || got.PUUID != "support" {
+		t.Errorf("opponent of the support = %+v, want support", got)
+	}
+
+	match.Info.Participants = match.Info.Participants[:2]
+	if got := findOpponent(match, &match.Info.Participants[0]); got != nil {
+		t.Errorf("opponent without an enemy ADC = %+v, want nil", got)
+	}
+}
+
+func TestOpponentStrengthWeighting(t *testing.T) {
+	match := &Match{}
+	match.Info.GameDuration = 1800
+	match.Info.Participants = []Participant{{TeamID: 100, Win: true}}
+	win := &match.Info.Participants[0]
+
+	// 同じ5勝5敗でも、強い対面に勝って弱い対面に負けたほうが勝率の指標は高い
+	tests := []struct {
+		name      string
+		winsVs    opponentStrength
+		lossesVs  opponentStrength
+		wantAbove bool // 補正なしの50%より高いか
+	}{
+		{"wins against stronger opponents", newOpponentStrength(2000, 1500), newOpponentStrength(1000, 1500), true},
+		{"wins against weaker opponents", newOpponentStrength(1000, 1500), newOpponentStrength(2000, 1500), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &weightedStats{}
+			loss := *win
+			loss.Win = false
+			for i := 0; i < 5; i++ {
+				stats.add(win, match, tt.winsVs.weight, tt.winsVs.expected)
+				stats.add(&loss, match, tt.lossesVs.weight, tt.lossesVs.expected)
+			}
+
+			got := stats.metric(MetricWinRate)
+			if got > 0.5 != tt.wantAbove {
+				t.Errorf("win rate metric = %f, want above 0.5: %v", got, tt.wantAbove)
+			}
+		})
+	}
+
+	// 補正しない場合は勝率そのもの
+	stats := &weightedStats{}
+	stats.add(win, match, neutralOpponent.weight, neutralOpponent.expected)
+	if got := stats.metric(MetricWinRate); got != 1 {
+		t.Errorf("win rate metric without opponents = %f, want 1", got)
+	}
+}
@@ -0,0 +1,89 @@
+package riotapi
+
+import "fmt"
+
+// 試合の判定の基準
+const (
+	// RemakeMaxDuration はこれより短い（秒）試合をリメイクとみなす
+	RemakeMaxDuration = 300
+	// LeaverTimePlayedRatio は試合時間に対するプレイ時間の割合がこれ未満の参加者を途中離脱とみなす
+	LeaverTimePlayedRatio = 0.8
+)
+
+// 途中離脱者のいる試合の扱い
+const (
+	LeaversExclude    = "exclude"    // 集計から除外する（デフォルト）
+	LeaversDownweight = "downweight" // leaverGameWeightの重みで集計する
+	LeaversInclude    = "include"    // 通常の試合と同じく集計する
+)
+
+// leaverGameWeight はdownweightで途中離脱者のいる試合に掛ける重み
+const leaverGameWeight = 0.5
+
+// matchQuality は集計に使う試合としての状態
+type matchQuality int
+
+const (
+	matchNormal matchQuality = iota // 通常の試合
+	matchRemake                     // リメイク（リメイク投票での終了・極端に短い試合）
+	matchLeaver                     // 途中離脱（AFK）の参加者がいる試合
+)
+
+// checkMatch は試合がリメイクか、途中離脱の参加者がいるかを判定する
+// マッチ履歴を集計する全ての機能で同じ基準を使う
+func checkMatch(match *Match) matchQuality {
+	if match.Info.GameDuration < RemakeMaxDuration {
+		return matchRemake
+	}
+	for _, p := range match.Info.Participants {
+		if p.GameEndedInEarlySurrender {
+			return matchRemake
+		}
+	}
+
+	for _, p := range match.Info.Participants {
+		if isLeaver(p, match.Info.GameDuration) {
+			return matchLeaver
+		}
+	}
+	return matchNormal
+}
+
+// isLeaver は参加者が途中離脱（AFK）したか判定する
+// ゴールドを全く得ていない、またはプレイ時間が試合時間に対して短すぎる場合
+// （プレイ時間が記録されていない古い試合はゴールドだけで判定する）
+func isLeaver(p Participant, duration int) bool {
+	if p.GoldEarned == 0 {
+		return true
+	}
+	return p.TimePlayed > 0 && float64(p.TimePlayed) < LeaverTimePlayedRatio*float64(duration)
+}
+
+// validateLeavers は途中離脱者のいる試合の扱いが正しいかチェックする
+func validateLeavers(leavers string) error {
+	switch leavers {
+	case "", LeaversExclude, LeaversDownweight, LeaversInclude:
+		return nil
+	}
+	return fmt.Errorf("途中離脱者のいる試合の扱いが不明です: %s", leavers)
+}
+
+// qualityWeight は試合の状態と途中離脱者の扱いから、集計に使う重みを返す（0は除外）
+// 除外する場合はexcludedの該当する理由を数える
+func qualityWeight(match *Match, leavers string, excluded *ExcludedGames) float64 {
+	switch checkMatch(match) {
+	case matchRemake:
+		excluded.Remake++
+		return 0
+	case matchLeaver:
+		switch leavers {
+		case LeaversInclude:
+			return 1
+		case LeaversDownweight:
+			return leaverGameWeight
+		}
+		excluded.Leaver++
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,142 @@
+package riotapi
+
+import "testing"
+
+// qualityMatch はduration秒の10人の試合を返す（全員が最後までプレイしてゴールドを得ている）
+func qualityMatch(duration int) *Match {
+	match := &Match{}
+	match.Info.GameDuration = duration
+	for i := 0; i < 10; i++ {
+		match.Info.Participants = append(match.Info.Participants, Participant{
+			TeamID:     100 + 100*(i/5),
+			GoldEarned: 10000,
+			TimePlayed: duration,
+		})
+	}
+	return match
+}
+
+func TestCheckMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		match func() *Match
+		want  matchQuality
+	}{
+		{
+			name:  "normal",
+			match: func() *Match { return qualityMatch(1800) },
+			want:  matchNormal,
+		},
+		{
+			name:  "short game is a remake",
+			match: func() *Match { return qualityMatch(RemakeMaxDuration - 1) },
+			want:  matchRemake,
+		},
+		{
+			name: "early surrender is a remake",
+			match: func() *Match {
+				m := qualityMatch(900)
+				m.Info.Participants[7].GameEndedInEarlySurrender = true
+				return m
+			},
+			want: matchRemake,
+		},
+		{
+			// 15分での降参は勝敗の決まった通常の試合
+			name: "ordinary early surrender is not a remake",
+			match: func() *Match {
+				m := qualityMatch(900)
+				for i := 0; i < 5; i++ {
+					m.Info.Participants[i].TeamEarlySurrendered = true
+				}
+				return m
+			},
+			want: matchNormal,
+		},
+		{
+			name: "remake wins over leaver",
+			match: func() *Match {
+				m := qualityMatch(200)
+				m.Info.Participants[0].GoldEarned = 0
+				return m
+			},
+			want: matchRemake,
+		},
+		{
+			name: "no gold is a leaver",
+			match: func() *Match {
+				m := qualityMatch(1800)
+				m.Info.Participants[4].GoldEarned = 0
+				return m
+			},
+			want: matchLeaver,
+		},
+		{
+			name: "short time played is a leaver",
+			match: func() *Match {
+				m := qualityMatch(1800)
+				m.Info.Participants[9].TimePlayed = 1000
+				return m
+			},
+			want: matchLeaver,
+		},
+	}
+
+	// 試合の状態と途中離脱者の扱いごとの重み
+	weights := map[matchQuality]map[string]float64{
+		matchNormal: {LeaversExclude: 1, LeaversDownweight: 1, LeaversInclude: 1},
+		matchRemake: {LeaversExclude: 0, LeaversDownweight: 0, LeaversInclude: 0},
+		matchLeaver: {LeaversExclude: 0, LeaversDownweight: leaverGameWeight, LeaversInclude: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkMatch(tt.match()); got != tt.want {
+				t.Fatalf("checkMatch() = %d, want %d", got, tt.want)
+			}
+			for leavers, want := range weights[tt.want] {
+				var excluded ExcludedGames
+				if got := qualityWeight(tt.match(), leavers, &excluded); got != want {
+					t.Errorf("qualityWeight(%s) = %v, want %v", leavers, got, want)
+				}
+				if want == 0 && excluded.Total() != 1 {
+					t.Errorf("qualityWeight(%s) excluded %+v, want one reason counted", leavers, excluded)
+				}
+			}
+		})
+	}
+}
+
+func TestIsLeaver(t *testing.T) {
+	const duration = 1800
+	tests := []struct {
+		name        string
+		participant Participant
+		want        bool
+	}{
+		{"played the whole game", Participant{GoldEarned: 10000, TimePlayed: duration}, false},
+		{"no gold", Participant{GoldEarned: 0, TimePlayed: duration}, true},
+		{"just over the ratio", Participant{GoldEarned: 8000, TimePlayed: int(LeaverTimePlayedRatio * duration)}, false},
+		{"under the ratio", Participant{GoldEarned: 8000, TimePlayed: int(LeaverTimePlayedRatio*duration) - 1}, true},
+		{"old match without time played", Participant{GoldEarned: 8000}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isLeaver(tt.participant, duration); got != tt.want {
+				t.Errorf("isLeaver() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLeavers(t *testing.T) {
+	for _, leavers := range []string{"", LeaversExclude, LeaversDownweight, LeaversInclude} {
+		if err := validateLeavers(leavers); err != nil {
+			t.Errorf("validateLeavers(%q): %v", leavers, err)
+		}
+	}
+	if err := validateLeavers("ignore"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
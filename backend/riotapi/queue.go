@@ -0,0 +1,39 @@
+package riotapi
+
+// キューID
+const (
+	QueueNormalDraft = 400 // ノーマル（ドラフトピック）
+	QueueRankedSolo  = 420 // ランク（ソロ/デュオ）
+	QueueNormalBlind = 430 // ノーマル（ブラインドピック）
+	QueueRankedFlex  = 440 // ランク（フレックス）
+	QueueARAM        = 450 // ARAM（ハウリングアビス）
+	QueueQuickplay   = 490 // クイックプレイ
+)
+
+// MapSummonersRift はサモナーズリフトのマップID
+const MapSummonersRift = 11
+
+// DefaultRoleQueues はロール別MMRの計算に使うデフォルトのキュー（ランク ソロ/フレックス、ノーマルドラフト）
+var DefaultRoleQueues = []int{QueueRankedSolo, QueueRankedFlex, QueueNormalDraft}
+
+// MatchIDFilter はマッチID取得時のAPI側の絞り込み条件
+type MatchIDFilter struct {
+	Queue int    // キューID（0は指定なし）
+	Type  string // 試合タイプ（"ranked"、"normal"等。空は指定なし）
+}
+
+// ExcludedGames は集計から除外したゲーム数（理由別）
+type ExcludedGames struct {
+	Queue       int `json:"queue"`       // 対象外のキュー（ARAM、URF、アリーナ、Bot戦等）
+	Map         int `json:"map"`         // サモナーズリフト以外のマップ
+	NoRole      int `json:"noRole"`      // ロールが判明しない
+	NotInMatch  int `json:"notInMatch"`  // 試合にプレイヤーが見つからない
+	FetchFailed int `json:"fetchFailed"` // 試合の取得に失敗
+	Remake      int `json:"remake"`      // リメイク（リメイク投票での終了・極端に短い試合）
+	Leaver      int `json:"leaver"`      // 途中離脱（AFK）の参加者がいる
+}
+
+// Total は除外したゲーム数の合計を返す
+func (e ExcludedGames) Total() int {
+	return e.Queue + e.Map + e.NoRole + e.NotInMatch + e.FetchFailed + e.Remake + e.Leaver
+}
@@ -2,21 +2,97 @@ package riotapi
 
 import (
 	"fmt"
+	"lol-team-backend/rating"
 	"math"
+	"sort"
+	"time"
 )
 
 // RoleMMRResult はロール別MMRの計算結果
 type RoleMMRResult struct {
-	Role        string  `json:"role"`        // ロール名
-	MMR         int     `json:"mmr"`         // 計算されたMMR
-	GamesPlayed int     `json:"gamesPlayed"` // このロールでプレイしたゲーム数
-	WinRate     float64 `json:"winRate"`     // 勝率
-	AverageKDA  float64 `json:"averageKda"`  // 平均KDA
-	AverageCS   float64 `json:"averageCs"`   // 平均CS/min
-	BaseRating  int     `json:"baseRating"`  // ベースレーティング（ランクから）
-	Confidence  float64 `json:"confidence"`  // 信頼度（0-1、ゲーム数に基づく）
+	Role           string               `json:"role"`           // ロール名
+	MMR            int                  `json:"mmr"`            // 計算されたMMR（直近の試合ほど重視）
+	RawMMR         int                  `json:"rawMmr"`         // 時間減衰なしのMMR（全試合を同じ重みで計算）
+	HalfLifeDays   float64              `json:"halfLifeDays"`   // 時間減衰の半減期（日、0は減衰なし）
+	GamesPlayed    int                  `json:"gamesPlayed"`    // このロールでプレイしたゲーム数
+	Share          float64              `json:"share"`          // ロールが判明したゲームのうちこのロールの割合（%）
+	WinRate        float64              `json:"winRate"`        // 勝率
+	AverageKDA     float64              `json:"averageKda"`     // 平均KDA
+	AverageCS      float64              `json:"averageCs"`      // 平均CS/min
+	BaseRating     int                  `json:"baseRating"`     // ベースレーティング（ランクから）
+	Confidence     float64              `json:"confidence"`     // 信頼度（0-1、glickoはRDから、confidenceはゲーム数から）
+	Model          string               `json:"model"`          // 使用したレーティングモデル
+	Deviation      int                  `json:"deviation"`      // MMRの不確かさ（RD、標準偏差に相当）
+	OpponentsRated int                  `json:"opponentsRated"` // 対面のランクが分かったゲーム数（OpponentStrength指定時）
+	OpponentRating int                  `json:"opponentRating"` // 対面の平均レーティング（ランクが分かったゲームのみ）
+	RatingLow      int                  `json:"ratingLow"`      // MMRの95%区間の下限（MMR - 2×RD）
+	RatingHigh     int                  `json:"ratingHigh"`     // MMRの95%区間の上限（MMR + 2×RD）
+	Excluded       ExcludedGames        `json:"excluded"`       // 集計から除外したゲーム数（理由別）
+	Downweighted   int                  `json:"downweighted"`   // 途中離脱者がいるため重みを下げて集計したゲーム数
+	Contributions  []MetricContribution `json:"contributions"`  // 評価指標ごとのMMRへの寄与
 }
 
+// AllRoleMMRResult は全ロールのMMRの計算結果
+type AllRoleMMRResult struct {
+	Roles        []RoleMMRResult `json:"roles"`        // ロールごとの結果（TOP, JUNGLE, MID, ADC, SUPPORTの順）
+	MainRole     string          `json:"mainRole"`     // 最も多くプレイしたロール（ゲームがない場合は空）
+	TotalGames   int             `json:"totalGames"`   // ロールが判明したゲーム数
+	BaseRating   int             `json:"baseRating"`   // ベースレーティング（ランクから）
+	Excluded     ExcludedGames   `json:"excluded"`     // 集計から除外したゲーム数（理由別）
+	Downweighted int             `json:"downweighted"` // 途中離脱者がいるため重みを下げて集計したゲーム数
+}
+
+// レーティングモデル
+const (
+	ModelGlicko     = "glicko"     // 試合結果を日ごとのレーティング期間としてGlicko-2で更新し、RDを求める（デフォルト）
+	ModelConfidence = "confidence" // ゲーム数の二次関数の信頼度で補正を抑える（従来の計算）
+)
+
+// RoleMMROptions はロール別MMRの計算オプション
+type RoleMMROptions struct {
+	// Model はレーティングモデル（"glicko"（デフォルト）、"confidence"）
+	Model string `json:"model,omitempty"`
+	// Blend はベースレーティングのソロ・フレックスのブレンド方針
+	Blend BlendPolicy `json:"blend"`
+	// Queues は集計するキューID（デフォルト: DefaultRoleQueues）。サモナーズリフト以外の試合は常に除外する
+	Queues []int `json:"queues,omitempty"`
+	// HalfLifeDays は試合の重みが半分になるまでの日数（デフォルト: 30、負の値で減衰なし）
+	HalfLifeDays float64 `json:"halfLifeDays,omitempty"`
+	// Scoring は補正に使う評価指標の方針（"role": ロール別（デフォルト）、"classic": 勝率・KDA・CS/minのみ）
+	Scoring string `json:"scoring,omitempty"`
+	// Laning がtrueの場合は各試合のタイムラインを取得し、レーン戦の指標（CS@10、対面とのゴールド差）を補正に加える
+	// （試合ごとにAPIの呼び出しが1回増える）
+	Laning bool `json:"laning,omitempty"`
+	// OpponentStrength がtrueの場合は各試合の対面のランクを取得し、強い対面との試合ほど重く、
+	// 勝率は対面との差から見た期待勝率を上回った分で評価する（対面ごとにAPIの呼び出しが1回増える）
+	OpponentStrength bool `json:"opponentStrength,omitempty"`
+	// Leavers は途中離脱（AFK）の参加者がいる試合の扱い（"exclude"（デフォルト）、"downweight"、"include"）
+	// リメイクは常に除外する
+	Leavers string `json:"leavers,omitempty"`
+	// Weights はロールごとの評価指標の重みの上書き（例: {"SUPPORT": {"visionPerMin": 1.5}}、0で無効）
+	Weights map[string]map[string]float64 `json:"weights,omitempty"`
+}
+
+// Validate はオプションが正しいかチェックする
+func (o RoleMMROptions) Validate() error {
+	if err := o.Blend.Validate(); err != nil {
+		return err
+	}
+	if o.Model != "" && o.Model != ModelGlicko && o.Model != ModelConfidence {
+		return fmt.Errorf("不明なレーティングモデルです: %s", o.Model)
+	}
+	if err := validateLeavers(o.Leavers); err != nil {
+		return err
+	}
+	return validateScoring(o.Scoring, o.Weights)
+}
+
+// DefaultHalfLifeDays は時間減衰のデフォルトの半減期（日）
+const DefaultHalfLifeDays = 30
+
+// Roles はロール別MMRを計算するロール（表示順）
+var Roles = []string{"TOP", "JUNGLE", "MID", "ADC", "SUPPORT"}
+
 // RoleStats はロール別の統計情報
 type RoleStats struct {
 	Wins          int
@@ -32,53 +108,169 @@ type RoleStats struct {
 // puuid: プレイヤーのPUUID
 // role: 計算対象のロール（TOP, JUNGLE, MID, ADC, SUPPORT）
 // matchCount: 分析するマッチ数（デフォルト: 20, 最大: 100）
-func (c *Client) GetRoleMMR(puuid string, role string, matchCount int) (*RoleMMRResult, error) {
+// opts: ベースレーティングのブレンド方針などのオプション
+func (c *Client) GetRoleMMR(puuid string, role string, matchCount int, opts RoleMMROptions) (*RoleMMRResult, error) {
+	history, err := c.collectRoleHistory(puuid, matchCount, opts)
+	if err != nil {
+		return nil, err
+	}
+	return history.result(role), nil
+}
+
+// GetAllRoleMMRs はマッチ履歴を1度だけ取得し、全ロールのMMRとロールごとのゲームの割合を計算する
+// 引数はGetRoleMMRと同じ
+func (c *Client) GetAllRoleMMRs(puuid string, matchCount int, opts RoleMMROptions) (*AllRoleMMRResult, error) {
+	history, err := c.collectRoleHistory(puuid, matchCount, opts)
+	if err != nil {
+		return nil, err
+	}
+	return history.allRoles(), nil
+}
+
+// allRoles は集計した統計から全ロールのMMRと最も多くプレイしたロールを求める
+func (h *roleHistory) allRoles() *AllRoleMMRResult {
+	result := &AllRoleMMRResult{
+		Roles:        make([]RoleMMRResult, 0, len(Roles)),
+		TotalGames:   h.totalGames,
+		BaseRating:   h.baseRating,
+		Excluded:     h.excluded,
+		Downweighted: h.downweighted,
+	}
+	mainGames := 0
+	for _, role := range Roles {
+		r := h.result(role)
+		result.Roles = append(result.Roles, *r)
+		if r.GamesPlayed > mainGames {
+			result.MainRole = role
+			mainGames = r.GamesPlayed
+		}
+	}
+	return result
+}
+
+// roleHistory はマッチ履歴から集計したロールごとの統計
+type roleHistory struct {
+	baseRating   int
+	matchCount   int
+	halfLife     float64 // 時間減衰の半減期（日、0は減衰なし）
+	model        string
+	scoring      string
+	laning       bool
+	weights      map[string]map[string]float64
+	stats        map[string]*RoleStats
+	raw          map[string]*weightedStats // 全試合を同じ重みで集計
+	decayed      map[string]*weightedStats // 時間減衰の重みで集計
+	outcomes     map[string][]gameOutcome  // 試合結果（Glickoの更新用）
+	now          time.Time                 // 集計した日時（最後の試合からの経過期間に使う）
+	totalGames   int                       // ロールが判明したゲーム数
+	excluded     ExcludedGames             // 除外したゲーム数
+	downweighted int                       // 途中離脱者がいるため重みを下げたゲーム数
+}
+
+// collectRoleHistory はマッチ履歴を取得し、全ロールの統計を集計する
+func (c *Client) collectRoleHistory(puuid string, matchCount int, opts RoleMMROptions) (*roleHistory, error) {
 	if matchCount <= 0 || matchCount > 100 {
 		matchCount = 20
 	}
 
 	// 1. ベースレーティングを取得（ランク情報から）
-	baseRating, err := c.getBaseRating(puuid)
+	base, err := c.getBaseRating(puuid, opts.Blend)
 	if err != nil {
 		return nil, fmt.Errorf("ベースレーティングの取得に失敗: %w", err)
 	}
 
-	// 2. マッチ履歴を取得
-	matchIDs, err := c.GetMatchIDs(puuid, 0, matchCount)
+	// 2. マッチ履歴を取得（キューごとにAPIで絞り込んで取得し、新しい順に合わせる）
+	queues := opts.Queues
+	if len(queues) == 0 {
+		queues = DefaultRoleQueues
+	}
+	allowedQueues := make(map[int]bool, len(queues))
+	for _, queue := range queues {
+		allowedQueues[queue] = true
+	}
+
+	matchIDs, err := c.GetMatchIDsByQueues(puuid, queues, matchCount)
 	if err != nil {
 		return nil, fmt.Errorf("マッチ履歴の取得に失敗: %w", err)
 	}
 
 	// 3. ロール別の統計を収集
-	stats := &RoleStats{}
-	analyzedMatches := 0
+	halfLife := opts.HalfLifeDays
+	if halfLife == 0 {
+		halfLife = DefaultHalfLifeDays
+	} else if halfLife < 0 {
+		halfLife = 0
+	}
+
+	now := time.Now()
+	opponents := make(opponentRatings)
+	model := opts.Model
+	if model == "" {
+		model = ModelGlicko
+	}
+
+	history := &roleHistory{
+		baseRating: base.Rating,
+		matchCount: matchCount,
+		halfLife:   halfLife,
+		model:      model,
+		now:        now,
+		scoring:    opts.Scoring,
+		laning:     opts.Laning,
+		weights:    opts.Weights,
+		stats:      make(map[string]*RoleStats, len(Roles)),
+		raw:        make(map[string]*weightedStats, len(Roles)),
+		decayed:    make(map[string]*weightedStats, len(Roles)),
+		outcomes:   make(map[string][]gameOutcome, len(Roles)),
+	}
+	for _, role := range Roles {
+		history.stats[role] = &RoleStats{}
+		history.raw[role] = &weightedStats{}
+		history.decayed[role] = &weightedStats{}
+	}
 
 	for _, matchID := range matchIDs {
 		match, err := c.GetMatchByID(matchID)
 		if err != nil {
+			history.excluded.FetchFailed++
 			continue // エラーの場合はスキップ
 		}
 
-		// プレイヤーの情報を検索
-		var participant *Participant
-		for i := range match.Info.Participants {
-			if match.Info.Participants[i].PUUID == puuid {
-				participant = &match.Info.Participants[i]
-				break
-			}
+		// 対象外のキュー・マップの試合はロールが意味を持たないのでスキップ
+		if !allowedQueues[match.Info.QueueID] {
+			history.excluded.Queue++
+			continue
+		}
+		if match.Info.MapID != MapSummonersRift {
+			history.excluded.Map++
+			continue
 		}
 
+		// リメイクと途中離脱者のいる試合は勝敗やKDAが実力を表さないので除外する（または重みを下げる）
+		quality := qualityWeight(match, opts.Leavers, &history.excluded)
+		if quality == 0 {
+			continue
+		}
+
+		// プレイヤーの情報を検索
+		participant := findParticipant(match, puuid)
 		if participant == nil {
+			history.excluded.NotInMatch++
 			continue
 		}
 
 		// ロールのマッピング（Riot APIのポジション名を標準化）
 		playerRole := normalizeRole(participant.TeamPosition)
-		if playerRole != role {
-			continue // 指定されたロール以外はスキップ
+		stats, ok := history.stats[playerRole]
+		if !ok {
+			history.excluded.NoRole++
+			continue
 		}
 
-		analyzedMatches++
+		history.totalGames++
+		if quality < 1 {
+			history.downweighted++
+		}
 
 		// 統計を集計
 		if participant.Win {
@@ -92,86 +284,217 @@ func (c *Client) GetRoleMMR(puuid string, role string, matchCount int) (*RoleMMR
 		stats.TotalAssists += participant.Assists
 		stats.TotalCS += participant.TotalMinionsKilled + participant.NeutralMinionsKilled
 		stats.TotalDuration += match.Info.GameDuration
+
+		// 評価指標用の統計（時間減衰なし・あり）
+		end := gameEndTime(match.Info)
+		weight := decayWeight(end, now, halfLife)
+		opponent := neutralOpponent
+		if opts.OpponentStrength {
+			opponent = c.opponentStrength(match, participant, base.Rating, opts.Blend, opponents)
+		}
+		history.raw[playerRole].add(participant, match, quality*opponent.weight, opponent.expected)
+		history.decayed[playerRole].add(participant, match, quality*weight*opponent.weight, opponent.expected)
+		history.outcomes[playerRole] = append(history.outcomes[playerRole],
+			gameOutcome{end: end, win: participant.Win, weight: quality, opponent: opponent})
+
+		// レーン戦の指標（タイムラインが取得できない試合は他の指標だけで評価する）
+		if opts.Laning {
+			if timeline, err := c.GetMatchTimelineByID(matchID); err == nil {
+				if game, ok := analyzeLaning(match, timeline, participant); ok {
+					history.raw[playerRole].addLaning(game, quality*opponent.weight)
+					history.decayed[playerRole].addLaning(game, quality*weight*opponent.weight)
+				}
+			}
+		}
+	}
+
+	return history, nil
+}
+
+// result は集計した統計から指定ロールのMMRを計算する
+func (h *roleHistory) result(role string) *RoleMMRResult {
+	stats, ok := h.stats[role]
+	analyzedMatches := 0
+	if ok {
+		analyzedMatches = stats.games()
 	}
 
 	// 4. MMRを計算
 	if analyzedMatches == 0 {
+		deviation := int(math.Round(rating.DefaultGlicko.InitialDeviation))
 		return &RoleMMRResult{
-			Role:        role,
-			MMR:         baseRating,
-			GamesPlayed: 0,
-			BaseRating:  baseRating,
-			Confidence:  0.0,
-		}, nil
+			Role:         role,
+			MMR:          h.baseRating,
+			RawMMR:       h.baseRating,
+			HalfLifeDays: h.halfLife,
+			GamesPlayed:  0,
+			BaseRating:   h.baseRating,
+			Confidence:   0.0,
+			Model:        h.model,
+			Deviation:    deviation,
+			RatingLow:    clampRating(h.baseRating - 2*deviation),
+			RatingHigh:   clampRating(h.baseRating + 2*deviation),
+			Excluded:     h.excluded,
+		}
 	}
 
-	mmr := calculateMMR(baseRating, stats, analyzedMatches)
+	profile := scoringProfile(h.scoring, role, h.laning, h.weights)
+	var base, rawBase, deviation int
+	var mmrConfidence, confidence float64
+	if h.model == ModelGlicko {
+		// 勝敗はGlickoの更新でレーティングに反映するので、勝率の補正は加えない
+		delete(profile, MetricWinRate)
+		g := h.glicko(role, true)
+		base = int(math.Round(g.Rating))
+		rawBase = int(math.Round(h.glicko(role, false).Rating))
+		deviation = int(math.Round(g.Deviation))
+		mmrConfidence = glickoConfidence(g.Deviation)
+		confidence = mmrConfidence
+	} else {
+		// サンプル数による信頼度（ゲーム数が少ない場合は補正を抑える）
+		base = h.baseRating
+		rawBase = base
+		mmrConfidence = calculateConfidence(analyzedMatches, 20)
+		deviation = int(math.Round(math.Max(rating.DefaultGlicko.InitialDeviation*(1-mmrConfidence), rating.DefaultGlicko.MinDeviation)))
+		confidence = calculateConfidence(analyzedMatches, h.matchCount)
+	}
+	rawMMR, _ := scoreMMR(rawBase, h.raw[role], profile, mmrConfidence)
+	mmr, contributions := scoreMMR(base, h.decayed[role], profile, mmrConfidence)
 
 	// 5. 各種統計を計算
 	winRate := float64(stats.Wins) / float64(analyzedMatches) * 100
+	opponentsRated, opponentTotal, downweighted := 0, 0, 0
+	for _, o := range h.outcomes[role] {
+		if o.weight < 1 {
+			downweighted++
+		}
+		if o.opponent.rated {
+			opponentsRated++
+			opponentTotal += o.opponent.rating
+		}
+	}
+	opponentRating := 0
+	if opponentsRated > 0 {
+		opponentRating = opponentTotal / opponentsRated
+	}
 	averageKDA := calculateKDA(stats.TotalKills, stats.TotalDeaths, stats.TotalAssists)
 	averageCS := calculateCSPerMin(stats.TotalCS, stats.TotalDuration)
-	confidence := calculateConfidence(analyzedMatches, matchCount)
 
 	return &RoleMMRResult{
-		Role:        role,
-		MMR:         mmr,
-		GamesPlayed: analyzedMatches,
-		WinRate:     winRate,
-		AverageKDA:  averageKDA,
-		AverageCS:   averageCS,
-		BaseRating:  baseRating,
-		Confidence:  confidence,
-	}, nil
+		Role:           role,
+		MMR:            mmr,
+		RawMMR:         rawMMR,
+		HalfLifeDays:   h.halfLife,
+		GamesPlayed:    analyzedMatches,
+		Share:          float64(analyzedMatches) / float64(h.totalGames) * 100,
+		WinRate:        winRate,
+		AverageKDA:     averageKDA,
+		AverageCS:      averageCS,
+		BaseRating:     h.baseRating,
+		Confidence:     confidence,
+		Model:          h.model,
+		Deviation:      deviation,
+		OpponentsRated: opponentsRated,
+		OpponentRating: opponentRating,
+		RatingLow:      clampRating(mmr - 2*deviation),
+		RatingHigh:     clampRating(mmr + 2*deviation),
+		Excluded:       h.excluded,
+		Downweighted:   downweighted,
+		Contributions:  contributions,
+	}
 }
 
-// getBaseRating はプレイヤーのベースレーティングを取得
-func (c *Client) getBaseRating(puuid string) (int, error) {
-	entries, err := c.GetLeagueEntriesByPUUID(puuid)
-	if err != nil {
-		return 0, err
+// opponentDeviation はGlickoの更新で相手チームのレーティングとみなすベースレーティングの不確かさ
+// （マッチングで近いレーティングの相手と組まれる前提）
+const opponentDeviation = 150
+
+// gameOutcome はGlickoの更新に使う1試合の結果
+type gameOutcome struct {
+	end      time.Time
+	win      bool
+	weight   float64          // 試合の重み（途中離脱者がいる試合は結果を50%に近づける）
+	opponent opponentStrength // 対面のランクが分かった場合はその強さを相手のレーティングにする
+}
+
+// glicko はロールの試合結果からGlicko-2のレーティングを求める
+// decayedがtrueの場合は時間減衰の半減期で古い試合ほど軽く扱う
+func (h *roleHistory) glicko(role string, decayed bool) rating.Glicko {
+	halfLife := 0.0
+	if decayed {
+		halfLife = h.halfLife
 	}
+	return glickoOutcomes(h.baseRating, h.outcomes[role], h.now, halfLife)
+}
+
+// glickoOutcomes は試合結果を古い順に1日ごとのレーティング期間としてGlicko-2で更新する
+// ベースレーティングを初期値（事前分布）とし、試合のない日と最後の試合から集計日時までの期間はRDが増える
+// halfLifeDays: 試合の重みが半分になるまでの日数（0は減衰なし）
+func glickoOutcomes(baseRating int, history []gameOutcome, now time.Time, halfLifeDays float64) rating.Glicko {
+	cfg := rating.DefaultGlicko
+	g := cfg.NewGlicko(float64(baseRating))
+
+	outcomes := append([]gameOutcome(nil), history...)
+	sort.Slice(outcomes, func(i, j int) bool { return outcomes[i].end.Before(outcomes[j].end) })
+
+	day := func(t time.Time) int { return int(t.Unix() / 86400) }
+	var results []rating.GlickoResult
+	for i, o := range outcomes {
+		score := 0.5 - o.weight/2
+		if o.win {
+			score = 0.5 + o.weight/2
+		}
+		result := rating.GlickoResult{
+			Opponent:          float64(baseRating),
+			OpponentDeviation: opponentDeviation,
+			Score:             score,
+			Weight:            decayWeight(o.end, now, halfLifeDays),
+		}
+		if o.opponent.rated {
+			result.Opponent = float64(o.opponent.rating)
+		}
+		results = append(results, result)
 
-	// ソロランクを優先
-	for _, entry := range entries {
-		if entry.QueueType == "RANKED_SOLO_5x5" {
-			return tierToRating(entry.Tier, entry.Rank, entry.LeaguePoints), nil
+		// 同じ日の試合をまとめて1期間として更新する
+		if i+1 < len(outcomes) && day(outcomes[i+1].end) == day(o.end) {
+			continue
+		}
+		g = cfg.Update(g, results)
+		results = results[:0]
+		if i+1 < len(outcomes) {
+			g = cfg.Idle(g, day(outcomes[i+1].end)-day(o.end)-1)
 		}
 	}
+	if len(outcomes) > 0 {
+		g = cfg.Idle(g, day(now)-day(outcomes[len(outcomes)-1].end))
+	}
+	return g
+}
+
+// glickoConfidence はRDから信頼度を計算（0.0 ~ 1.0、RDが初期値のとき0）
+func glickoConfidence(deviation float64) float64 {
+	x := deviation / rating.DefaultGlicko.InitialDeviation
+	return math.Max(0, 1-x*x)
+}
 
-	// ソロランクがない場合は最初のエントリー
-	if len(entries) > 0 {
-		return tierToRating(entries[0].Tier, entries[0].Rank, entries[0].LeaguePoints), nil
+// clampRating はレーティングを0 ~ 上限に収める
+func clampRating(r int) int {
+	return min(max(r, 0), rating.DefaultScale.Max())
+}
+
+// getBaseRating はプレイヤーのベースレーティングを取得
+func (c *Client) getBaseRating(puuid string, policy BlendPolicy) (*BaseRating, error) {
+	entries, err := c.GetLeagueEntriesByPUUID(puuid)
+	if err != nil {
+		return nil, err
 	}
 
-	// ランク情報がない場合はデフォルト（シルバー相当）
-	return 800, nil
+	// ソロ・フレックスを方針に従ってブレンド（ランク情報がない場合はシルバー相当）
+	return BlendRating(entries, policy), nil
 }
 
-// tierToRating はティア情報をレーティングに変換
-func tierToRating(tier, rank string, lp int) int {
-	tierValues := map[string]int{
-		"IRON":        0,
-		"BRONZE":      400,
-		"SILVER":      800,
-		"GOLD":        1200,
-		"PLATINUM":    1600,
-		"EMERALD":     2000,
-		"DIAMOND":     2400,
-		"MASTER":      2800,
-		"GRANDMASTER": 3200,
-		"CHALLENGER":  3600,
-		"UNRANKED":    800,
-	}
-
-	rankValues := map[string]int{
-		"IV":  0,
-		"III": 100,
-		"II":  200,
-		"I":   300,
-	}
-
-	return tierValues[tier] + rankValues[rank] + lp
+// games はこのロールでプレイしたゲーム数を返す
+func (s *RoleStats) games() int {
+	return s.Wins + s.Losses
 }
 
 // normalizeRole はRiot APIのロール名を標準形式に変換
@@ -193,50 +516,24 @@ func normalizeRole(position string) string {
 	return position
 }
 
-// calculateMMR はベースレーティングと統計からMMRを計算
-func calculateMMR(baseRating int, stats *RoleStats, gamesPlayed int) int {
-	totalGames := stats.Wins + stats.Losses
-	if totalGames == 0 {
-		return baseRating
-	}
-
-	// 勝率による補正（-200 ~ +200）
-	winRate := float64(stats.Wins) / float64(totalGames)
-	winRateAdjustment := (winRate - 0.5) * 400
-
-	// KDAによる補正（-100 ~ +100）
-	kda := calculateKDA(stats.TotalKills, stats.TotalDeaths, stats.TotalAssists)
-	kdaAdjustment := 0.0
-	if kda < 2.0 {
-		kdaAdjustment = (kda - 2.0) * 50 // KDA 2.0未満はマイナス
-	} else if kda > 3.0 {
-		kdaAdjustment = math.Min((kda-3.0)*50, 100) // KDA 3.0以上はプラス（最大+100）
+// gameEndTime は試合の終了日時を返す（終了日時がない古い試合は開始日時 + 試合時間）
+func gameEndTime(info MatchInfo) time.Time {
+	if info.GameEndTimestamp > 0 {
+		return time.UnixMilli(info.GameEndTimestamp)
 	}
+	return time.UnixMilli(info.GameCreation).Add(time.Duration(info.GameDuration) * time.Second)
+}
 
-	// CS効率による補正（-50 ~ +50）
-	csPerMin := calculateCSPerMin(stats.TotalCS, stats.TotalDuration)
-	csAdjustment := 0.0
-	if csPerMin < 5.0 {
-		csAdjustment = (csPerMin - 5.0) * 10
-	} else if csPerMin > 7.0 {
-		csAdjustment = math.Min((csPerMin-7.0)*25, 50)
+// decayWeight は試合の古さに応じた重み（半減期ごとに半分）を返す。halfLifeDaysが0の場合は常に1
+func decayWeight(end, now time.Time, halfLifeDays float64) float64 {
+	if halfLifeDays <= 0 {
+		return 1
 	}
-
-	// サンプル数による信頼度（ゲーム数が少ない場合は補正を抑える）
-	confidence := calculateConfidence(gamesPlayed, 20)
-
-	// 最終MMR計算
-	totalAdjustment := (winRateAdjustment + kdaAdjustment + csAdjustment) * confidence
-	finalMMR := baseRating + int(totalAdjustment)
-
-	// MMRの範囲を制限（0 ~ 4000）
-	if finalMMR < 0 {
-		finalMMR = 0
-	} else if finalMMR > 4000 {
-		finalMMR = 4000
+	ageDays := now.Sub(end).Hours() / 24
+	if ageDays <= 0 {
+		return 1
 	}
-
-	return finalMMR
+	return math.Pow(0.5, ageDays/halfLifeDays)
 }
 
 // calculateKDA はKDAを計算
@@ -249,11 +546,16 @@ func calculateKDA(kills, deaths, assists int) float64 {
 
 // calculateCSPerMin は分あたりのCSを計算
 func calculateCSPerMin(totalCS, totalDurationSeconds int) float64 {
+	return perMinute(totalCS, totalDurationSeconds)
+}
+
+// perMinute は合計値を試合時間（秒）で割った分あたりの値を計算
+func perMinute(total, totalDurationSeconds int) float64 {
 	if totalDurationSeconds == 0 {
 		return 0
 	}
 	minutes := float64(totalDurationSeconds) / 60.0
-	return float64(totalCS) / minutes
+	return float64(total) / minutes
 }
 
 // calculateConfidence はサンプル数から信頼度を計算（0.0 ~ 1.0）
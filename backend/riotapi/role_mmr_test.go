@@ -0,0 +1,166 @@
+package riotapi
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testRoleHistory はロールごとの試合結果だけを持つ集計を返す（評価指標の統計は空）
+func testRoleHistory(now time.Time, model string, halfLife float64, outcomes map[string][]gameOutcome) *roleHistory {
+	h := &roleHistory{
+		baseRating: 1500,
+		halfLife:   halfLife,
+		model:      model,
+		now:        now,
+		stats:      make(map[string]*RoleStats),
+		raw:        make(map[string]*weightedStats),
+		decayed:    make(map[string]*weightedStats),
+		outcomes:   outcomes,
+	}
+	for _, role := range Roles {
+		h.stats[role] = &RoleStats{}
+		h.raw[role] = &weightedStats{}
+		h.decayed[role] = &weightedStats{}
+	}
+	for role, games := range outcomes {
+		for _, o := range games {
+			if o.win {
+				h.stats[role].Wins++
+			} else {
+				h.stats[role].Losses++
+			}
+		}
+		h.matchCount += len(games)
+		h.totalGames += len(games)
+	}
+	return h
+}
+
+func TestGlickoMMRFollowsHalfLife(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// 1年前に10連敗し、直近10日で10連勝した
+	var outcomes []gameOutcome
+	for i := 0; i < 10; i++ {
+		outcomes = append(outcomes, gameOutcome{end: now.AddDate(0, 0, -365-i), win: false, weight: 1, opponent: neutralOpponent})
+		outcomes = append(outcomes, gameOutcome{end: now.AddDate(0, 0, -1-i), win: true, weight: 1, opponent: neutralOpponent})
+	}
+
+	flat := testRoleHistory(now, ModelGlicko, 0, map[string][]gameOutcome{"MID": outcomes}).result("MID")
+	decayed := testRoleHistory(now, ModelGlicko, DefaultHalfLifeDays, map[string][]gameOutcome{"MID": outcomes}).result("MID")
+
+	if decayed.MMR <= flat.MMR {
+		t.Errorf("MMR with half-life %d = %d, want above the undecayed %d", DefaultHalfLifeDays, decayed.MMR, flat.MMR)
+	}
+	if flat.RawMMR != flat.MMR {
+		t.Errorf("without decay RawMMR %d and MMR %d should match", flat.RawMMR, flat.MMR)
+	}
+	if decayed.RawMMR != flat.RawMMR {
+		t.Errorf("RawMMR changed with the half-life: %d, want %d", decayed.RawMMR, flat.RawMMR)
+	}
+	if decayed.RawMMR == decayed.MMR {
+		t.Errorf("RawMMR and MMR are both %d under decay", decayed.MMR)
+	}
+}
+
+func TestAllRolesShare(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	games := func(n int) []gameOutcome {
+		outcomes := make([]gameOutcome, n)
+		for i := range outcomes {
+			outcomes[i] = gameOutcome{end: now.AddDate(0, 0, -1-i), win: i%2 == 0, weight: 1, opponent: neutralOpponent}
+		}
+		return outcomes
+	}
+
+	tests := []struct {
+		name     string
+		outcomes map[string][]gameOutcome
+		share    map[string]float64
+		mainRole string
+	}{
+		{"no games", map[string][]gameOutcome{}, map[string]float64{}, ""},
+		{"single role", map[string][]gameOutcome{"JUNGLE": games(8)}, map[string]float64{"JUNGLE": 100}, "JUNGLE"},
+		{
+			"split between roles",
+			map[string][]gameOutcome{"TOP": games(2), "MID": games(5), "SUPPORT": games(3)},
+			map[string]float64{"TOP": 20, "MID": 50, "SUPPORT": 30},
+			"MID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := testRoleHistory(now, ModelGlicko, DefaultHalfLifeDays, tt.outcomes).allRoles()
+			if result.MainRole != tt.mainRole {
+				t.Errorf("MainRole = %q, want %q", result.MainRole, tt.mainRole)
+			}
+			if len(result.Roles) != len(Roles) {
+				t.Fatalf("roles = %d, want %d", len(result.Roles), len(Roles))
+			}
+
+			total := 0.0
+			for i, r := range result.Roles {
+				if r.Role != Roles[i] {
+					t.Errorf("role %d = %s, want %s", i, r.Role, Roles[i])
+				}
+				if r.GamesPlayed != len(tt.outcomes[r.Role]) {
+					t.Errorf("%s GamesPlayed = %d, want %d", r.Role, r.GamesPlayed, len(tt.outcomes[r.Role]))
+				}
+				if math.Abs(r.Share-tt.share[r.Role]) > 1e-9 {
+					t.Errorf("%s Share = %.1f, want %.1f", r.Role, r.Share, tt.share[r.Role])
+				}
+				total += r.Share
+			}
+			if result.TotalGames > 0 && math.Abs(total-100) > 1e-9 {
+				t.Errorf("shares add up to %.1f, want 100", total)
+			}
+		})
+	}
+}
+
+func TestDecayWeight(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		end      time.Time
+		halfLife float64
+		want     float64
+	}{
+		{"no decay", now.AddDate(0, 0, -100), 0, 1},
+		{"negative half-life", now.AddDate(0, 0, -100), -5, 1},
+		{"just finished", now, 30, 1},
+		{"in the future", now.Add(time.Hour), 30, 1},
+		{"one half-life", now.AddDate(0, 0, -30), 30, 0.5},
+		{"two half-lives", now.AddDate(0, 0, -60), 30, 0.25},
+		{"half a day", now.Add(-12 * time.Hour), 1, math.Sqrt(0.5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decayWeight(tt.end, now, tt.halfLife); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("decayWeight = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGameEndTime(t *testing.T) {
+	end := time.Date(2026, 6, 1, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		info MatchInfo
+	}{
+		{"end timestamp", MatchInfo{GameEndTimestamp: end.UnixMilli(), GameCreation: end.Add(-time.Hour).UnixMilli()}},
+		{"creation plus duration", MatchInfo{GameCreation: end.Add(-30 * time.Minute).UnixMilli(), GameDuration: 1800}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gameEndTime(tt.info); !got.Equal(end) {
+				t.Errorf("gameEndTime = %v, want %v", got, end)
+			}
+		})
+	}
+}
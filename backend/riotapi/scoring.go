@@ -0,0 +1,313 @@
+package riotapi
+
+import (
+	"fmt"
+	"lol-team-backend/rating"
+	"math"
+)
+
+// MMRの補正に使う評価指標
+const (
+	MetricWinRate           = "winRate"               // 勝率
+	MetricKDA               = "kda"                   // KDA
+	MetricCSPerMin          = "csPerMin"              // CS/min
+	MetricVisionPerMin      = "visionPerMin"          // 視界スコア/min
+	MetricKillParticipation = "killParticipation"     // キル関与率
+	MetricDamageShare       = "damageShare"           // チーム内のチャンピオンへのダメージの割合
+	MetricObjectiveDamage   = "objectiveDamagePerMin" // オブジェクトへのダメージ/min
+	MetricFirstBlood        = "firstBlood"            // ファーストブラッド関与率（序盤のガンクの目安）
+	MetricCSAt10            = "csAt10"                // 10分時点のCS（タイムラインから）
+	MetricLaneGoldDiff      = "laneGoldDiff"          // 対面とのゴールド差@15（タイムラインから）
+)
+
+// 評価の方針
+const (
+	ScoringRole    = "role"    // ロール別のプロファイル
+	ScoringClassic = "classic" // 全ロール共通の勝率・KDA・CS/min（従来の計算）
+)
+
+// metricScale は評価指標の値を補正値に換算する基準
+// low〜highの範囲は補正なし、範囲外は1単位あたりbelow/aboveで補正し、絶対値をlimitまでに抑える
+type metricScale struct {
+	low, high    float64
+	below, above float64
+	limit        float64
+}
+
+// metricOrder は評価指標（表示順）
+var metricOrder = []string{
+	MetricWinRate, MetricKDA, MetricCSPerMin, MetricVisionPerMin,
+	MetricKillParticipation, MetricDamageShare, MetricObjectiveDamage, MetricFirstBlood,
+	MetricCSAt10, MetricLaneGoldDiff,
+}
+
+// metricScales は評価指標ごとの換算基準
+var metricScales = map[string]metricScale{
+	MetricWinRate:           {low: 0.5, high: 0.5, below: 400, above: 400, limit: 200},   // -200 ~ +200
+	MetricKDA:               {low: 2.0, high: 3.0, below: 50, above: 50, limit: 100},     // -100 ~ +100
+	MetricCSPerMin:          {low: 5.0, high: 7.0, below: 10, above: 25, limit: 50},      // -50 ~ +50
+	MetricVisionPerMin:      {low: 1.0, high: 2.0, below: 50, above: 50, limit: 50},      // -50 ~ +50
+	MetricKillParticipation: {low: 0.45, high: 0.6, below: 200, above: 200, limit: 50},   // -50 ~ +50
+	MetricDamageShare:       {low: 0.2, high: 0.28, below: 500, above: 500, limit: 50},   // -50 ~ +50
+	MetricObjectiveDamage:   {low: 400, high: 800, below: 0.1, above: 0.1, limit: 50},    // -50 ~ +50
+	MetricFirstBlood:        {low: 0.2, high: 0.35, below: 200, above: 200, limit: 50},   // -50 ~ +50
+	MetricCSAt10:            {low: 60, high: 80, below: 1.5, above: 1.5, limit: 50},      // -50 ~ +50
+	MetricLaneGoldDiff:      {low: -200, high: 200, below: 0.05, above: 0.05, limit: 75}, // -75 ~ +75
+}
+
+// classicProfile は全ロール共通の評価指標の重み
+var classicProfile = map[string]float64{
+	MetricWinRate:  1,
+	MetricKDA:      1,
+	MetricCSPerMin: 1,
+}
+
+// roleProfiles はロール別の評価指標の重み
+var roleProfiles = map[string]map[string]float64{
+	"TOP": {
+		MetricWinRate:     1,
+		MetricKDA:         1,
+		MetricCSPerMin:    1,
+		MetricDamageShare: 0.5,
+	},
+	"JUNGLE": {
+		MetricWinRate:           1,
+		MetricKDA:               1,
+		MetricObjectiveDamage:   1,
+		MetricFirstBlood:        1,
+		MetricKillParticipation: 0.5,
+	},
+	"MID": {
+		MetricWinRate:     1,
+		MetricKDA:         1,
+		MetricCSPerMin:    1,
+		MetricDamageShare: 1,
+	},
+	"ADC": {
+		MetricWinRate:     1,
+		MetricKDA:         1,
+		MetricCSPerMin:    1,
+		MetricDamageShare: 1,
+	},
+	"SUPPORT": {
+		MetricWinRate:           1,
+		MetricKDA:               1,
+		MetricVisionPerMin:      1,
+		MetricKillParticipation: 1,
+	},
+}
+
+// laningProfiles はレーン戦の指標を使う場合にロール別のプロファイルに加える重み
+var laningProfiles = map[string]map[string]float64{
+	"TOP":     {MetricCSAt10: 0.5, MetricLaneGoldDiff: 1},
+	"JUNGLE":  {MetricLaneGoldDiff: 0.5},
+	"MID":     {MetricCSAt10: 0.5, MetricLaneGoldDiff: 1},
+	"ADC":     {MetricCSAt10: 0.5, MetricLaneGoldDiff: 1},
+	"SUPPORT": {MetricLaneGoldDiff: 0.5},
+}
+
+// MetricContribution はMMRへの評価指標1つ分の寄与
+type MetricContribution struct {
+	Metric       string  `json:"metric"`       // 評価指標
+	Value        float64 `json:"value"`        // 指標の値
+	Weight       float64 `json:"weight"`       // プロファイルでの重み
+	Contribution int     `json:"contribution"` // MMRへの寄与（重みと信頼度を掛けた補正値）
+}
+
+// weightedStats は試合ごとに重みを掛けて集計した統計
+type weightedStats struct {
+	games           float64
+	wins            float64
+	kills           float64
+	deaths          float64
+	assists         float64
+	cs              float64
+	vision          float64
+	damage          float64
+	objectiveDamage float64
+	firstBlood      float64
+	teamKills       float64
+	teamDamage      float64
+	duration        float64 // 秒単位
+	winAdjustment   float64 // 対面の強さによる勝ち数の補正（期待勝率が50%より低い試合ほど正）
+	laneGames       float64 // タイムラインを分析した試合
+	csAt10          float64
+	laneGoldDiff    float64
+}
+
+// add は試合の統計を重みを掛けて加える
+// match: プレイヤーが参加した試合（チームのキル数・ダメージの集計に使う）
+// expected: 対面の強さから見た期待勝率（補正しない場合は0.5）
+func (w *weightedStats) add(p *Participant, match *Match, weight, expected float64) {
+	teamKills, teamDamage := 0, 0
+	for _, other := range match.Info.Participants {
+		if other.TeamID == p.TeamID {
+			teamKills += other.Kills
+			teamDamage += other.TotalDamageDealtToChampions
+		}
+	}
+
+	w.games += weight
+	if p.Win {
+		w.wins += weight
+	}
+	w.winAdjustment += weight * (0.5 - expected)
+	if p.FirstBloodKill || p.FirstBloodAssist {
+		w.firstBlood += weight
+	}
+	w.kills += weight * float64(p.Kills)
+	w.deaths += weight * float64(p.Deaths)
+	w.assists += weight * float64(p.Assists)
+	w.cs += weight * float64(p.TotalMinionsKilled+p.NeutralMinionsKilled)
+	w.vision += weight * float64(p.VisionScore)
+	w.damage += weight * float64(p.TotalDamageDealtToChampions)
+	w.objectiveDamage += weight * float64(p.DamageDealtToObjectives)
+	w.teamKills += weight * float64(teamKills)
+	w.teamDamage += weight * float64(teamDamage)
+	w.duration += weight * float64(match.Info.GameDuration)
+}
+
+// addLaning はタイムラインから求めたレーン戦の指標を重みを掛けて加える
+func (w *weightedStats) addLaning(game LaningGame, weight float64) {
+	w.laneGames += weight
+	w.csAt10 += weight * float64(game.CSAt10)
+	w.laneGoldDiff += weight * float64(game.laneGoldDiff())
+}
+
+// metric は評価指標の値を返す
+func (w *weightedStats) metric(name string) float64 {
+	minutes := w.duration / 60.0
+	switch name {
+	case MetricWinRate:
+		// 期待勝率を上回った分だけ50%から上げる（補正しない場合は勝率そのもの）
+		return ratio(w.wins+w.winAdjustment, w.games)
+	case MetricKDA:
+		if w.deaths == 0 {
+			return w.kills + w.assists
+		}
+		return (w.kills + w.assists) / w.deaths
+	case MetricCSPerMin:
+		return ratio(w.cs, minutes)
+	case MetricVisionPerMin:
+		return ratio(w.vision, minutes)
+	case MetricKillParticipation:
+		return ratio(w.kills+w.assists, w.teamKills)
+	case MetricDamageShare:
+		return ratio(w.damage, w.teamDamage)
+	case MetricObjectiveDamage:
+		return ratio(w.objectiveDamage, minutes)
+	case MetricFirstBlood:
+		return ratio(w.firstBlood, w.games)
+	case MetricCSAt10:
+		return ratio(w.csAt10, w.laneGames)
+	case MetricLaneGoldDiff:
+		return ratio(w.laneGoldDiff, w.laneGames)
+	}
+	return 0
+}
+
+// available は評価指標の値を計算できるだけの統計があるか判定する
+// （レーン戦の指標はタイムラインを分析できた試合がない場合は使わない）
+func (w *weightedStats) available(name string) bool {
+	switch name {
+	case MetricCSAt10, MetricLaneGoldDiff:
+		return w.laneGames > 0
+	}
+	return w.games > 0
+}
+
+// ratio は分母が0の場合に0を返す割り算
+func ratio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
+
+// adjustment は評価指標の値から補正値を返す
+func (s metricScale) adjustment(value float64) float64 {
+	switch {
+	case value < s.low:
+		return math.Max((value-s.low)*s.below, -s.limit)
+	case value > s.high:
+		return math.Min((value-s.high)*s.above, s.limit)
+	}
+	return 0
+}
+
+// validateScoring は評価の方針と重みの上書きが正しいかチェックする
+func validateScoring(scoring string, weights map[string]map[string]float64) error {
+	if scoring != "" && scoring != ScoringRole && scoring != ScoringClassic {
+		return fmt.Errorf("不明な評価の方針です: %s", scoring)
+	}
+	for role, metrics := range weights {
+		if _, ok := roleProfiles[role]; !ok {
+			return fmt.Errorf("不明なロールです: %s", role)
+		}
+		for metric, weight := range metrics {
+			if _, ok := metricScales[metric]; !ok {
+				return fmt.Errorf("不明な評価指標です: %s", metric)
+			}
+			if weight < 0 {
+				return fmt.Errorf("評価指標%sの重みが負です: %v", metric, weight)
+			}
+		}
+	}
+	return nil
+}
+
+// scoringProfile はロールの評価指標の重みを返す（weightsで指定された重みで上書きする）
+// laning: タイムラインから求めたレーン戦の指標を加える
+func scoringProfile(scoring, role string, laning bool, weights map[string]map[string]float64) map[string]float64 {
+	base := roleProfiles[role]
+	if scoring == ScoringClassic || base == nil {
+		base = classicProfile
+	}
+
+	profile := make(map[string]float64, len(base))
+	for metric, weight := range base {
+		profile[metric] = weight
+	}
+	if laning {
+		for metric, weight := range laningProfiles[role] {
+			profile[metric] = weight
+		}
+	}
+	for metric, weight := range weights[role] {
+		profile[metric] = weight
+	}
+	return profile
+}
+
+// scoreMMR はベースレーティングにプロファイルの評価指標の補正を信頼度で抑えて加え、
+// MMRと指標ごとの寄与を返す
+func scoreMMR(baseRating int, stats *weightedStats, profile map[string]float64, confidence float64) (int, []MetricContribution) {
+	total := 0.0
+	var contributions []MetricContribution
+	for _, metric := range metricOrder {
+		weight := profile[metric]
+		if weight == 0 || !stats.available(metric) {
+			continue
+		}
+		value := stats.metric(metric)
+		adjustment := metricScales[metric].adjustment(value) * weight * confidence
+		total += adjustment
+		contributions = append(contributions, MetricContribution{
+			Metric:       metric,
+			Value:        value,
+			Weight:       weight,
+			Contribution: int(math.Round(adjustment)),
+		})
+	}
+
+	finalMMR := baseRating + int(total)
+
+	// MMRの範囲を制限（0 ~ レーティングの上限）
+	if finalMMR < 0 {
+		finalMMR = 0
+	} else if finalMMR > rating.DefaultScale.Max() {
+		finalMMR = rating.DefaultScale.Max()
+	}
+
+	return finalMMR, contributions
+}
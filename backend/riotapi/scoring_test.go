@@ -0,0 +1,141 @@
+package riotapi
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestScoringProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		scoring string
+		role    string
+		laning  bool
+		weights map[string]map[string]float64
+		want    map[string]float64
+	}{
+		{"support profile", ScoringRole, "SUPPORT", false, nil, roleProfiles["SUPPORT"]},
+		{"default is the role profile", "", "JUNGLE", false, nil, roleProfiles["JUNGLE"]},
+		{"classic ignores the role", ScoringClassic, "SUPPORT", false, nil, classicProfile},
+		{"unknown role falls back to classic", ScoringRole, "FILL", false, nil, classicProfile},
+		{
+			"laning metrics are added",
+			ScoringRole, "MID", true, nil,
+			map[string]float64{MetricWinRate: 1, MetricKDA: 1, MetricCSPerMin: 1, MetricDamageShare: 1, MetricCSAt10: 0.5, MetricLaneGoldDiff: 1},
+		},
+		{
+			"weights override the profile",
+			ScoringRole, "TOP", false,
+			map[string]map[string]float64{"TOP": {MetricCSPerMin: 0, MetricVisionPerMin: 2}, "MID": {MetricKDA: 5}},
+			map[string]float64{MetricWinRate: 1, MetricKDA: 1, MetricCSPerMin: 0, MetricDamageShare: 0.5, MetricVisionPerMin: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoringProfile(tt.scoring, tt.role, tt.laning, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scoringProfile = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 返したプロファイルを変更しても共有のプロファイルは変わらない
+	scoringProfile(ScoringRole, "ADC", false, nil)[MetricKDA] = 10
+	if roleProfiles["ADC"][MetricKDA] != 1 {
+		t.Errorf("modifying a profile changed roleProfiles: %v", roleProfiles["ADC"])
+	}
+}
+
+func TestMetricScaleAdjustment(t *testing.T) {
+	s := metricScale{low: 5, high: 7, below: 10, above: 25, limit: 50}
+	tests := []struct {
+		value, want float64
+	}{
+		{6, 0},
+		{5, 0},
+		{7, 0},
+		{4, -10},
+		{8, 25},
+		{0, -50},
+		{100, 50},
+	}
+
+	for _, tt := range tests {
+		if got := s.adjustment(tt.value); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("adjustment(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestScoreMMRUsesRoleProfile(t *testing.T) {
+	// 10試合・各30分。勝率50%・KDA3.0・キル関与率60%は補正なし、CSは少なく視界スコアは多い
+	stats := &weightedStats{
+		games:     10,
+		wins:      5,
+		kills:     20,
+		deaths:    20,
+		assists:   40,
+		cs:        100,
+		vision:    900,
+		teamKills: 100,
+		duration:  10 * 1800,
+	}
+
+	tests := []struct {
+		name    string
+		scoring string
+		want    int
+		metrics []string
+	}{
+		{"support is scored on vision", ScoringRole, 1550, []string{MetricWinRate, MetricKDA, MetricVisionPerMin, MetricKillParticipation}},
+		{"classic penalizes low CS", ScoringClassic, 1454, []string{MetricWinRate, MetricKDA, MetricCSPerMin}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mmr, contributions := scoreMMR(1500, stats, scoringProfile(tt.scoring, "SUPPORT", false, nil), 1)
+			if mmr != tt.want {
+				t.Errorf("MMR = %d, want %d (%+v)", mmr, tt.want, contributions)
+			}
+			var metrics []string
+			for _, c := range contributions {
+				metrics = append(metrics, c.Metric)
+			}
+			if !reflect.DeepEqual(metrics, tt.metrics) {
+				t.Errorf("contributions = %v, want %v", metrics, tt.metrics)
+			}
+		})
+	}
+
+	// 信頼度0なら補正しない
+	if mmr, _ := scoreMMR(1500, stats, scoringProfile(ScoringRole, "SUPPORT", false, nil), 0); mmr != 1500 {
+		t.Errorf("MMR with zero confidence = %d, want 1500", mmr)
+	}
+}
+
+func TestValidateScoring(t *testing.T) {
+	tests := []struct {
+		name    string
+		scoring string
+		weights map[string]map[string]float64
+		wantErr bool
+	}{
+		{"defaults", "", nil, false},
+		{"classic", ScoringClassic, nil, false},
+		{"override", ScoringRole, map[string]map[string]float64{"SUPPORT": {MetricVisionPerMin: 2}}, false},
+		{"unknown scoring", "elo", nil, true},
+		{"unknown role", ScoringRole, map[string]map[string]float64{"FILL": {MetricKDA: 1}}, true},
+		{"unknown metric", ScoringRole, map[string]map[string]float64{"MID": {"pentakills": 1}}, true},
+		{"negative weight", ScoringRole, map[string]map[string]float64{"MID": {MetricKDA: -1}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateScoring(tt.scoring, tt.weights); (err != nil) != tt.wantErr {
+				t.Errorf("validateScoring error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"lol-team-backend/balance"
+	"sync"
+	"time"
+)
+
+// draftEntry は保存中のドラフトと最終更新日時
+type draftEntry struct {
+	draft     *balance.Draft
+	updatedAt time.Time
+}
+
+// DraftStore は進行中のキャプテンドラフトのインメモリ保存先
+type DraftStore struct {
+	mu     sync.Mutex
+	drafts map[string]draftEntry
+}
+
+// NewDraftStore は新しいドラフトストアを作成
+func NewDraftStore() *DraftStore {
+	return &DraftStore{
+		drafts: make(map[string]draftEntry),
+	}
+}
+
+// Create はドラフトを保存し、ドラフトIDを返す
+func (s *DraftStore) Create(draft *balance.Draft) (string, error) {
+	id, err := newID()
+	if err != nil {
+		return "", fmt.Errorf("ドラフトIDの生成に失敗: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range s.drafts {
+		if now.Sub(entry.updatedAt) > sessionTTL {
+			delete(s.drafts, key)
+		}
+	}
+	s.drafts[id] = draftEntry{draft: cloneDraft(draft), updatedAt: now}
+
+	return id, nil
+}
+
+// Update はドラフトをロックした状態でfnを実行し、エラーがなければ変更を保存する
+func (s *DraftStore) Update(id string, fn func(*balance.Draft) error) (*balance.Draft, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, exists := s.drafts[id]
+	if !exists {
+		return nil, fmt.Errorf("ドラフトが見つかりません: %s", id)
+	}
+
+	working := cloneDraft(entry.draft)
+	if err := fn(working); err != nil {
+		return nil, err
+	}
+
+	s.drafts[id] = draftEntry{draft: working, updatedAt: time.Now()}
+	return cloneDraft(working), nil
+}
+
+// cloneDraft はドラフトのディープコピーを返す
+func cloneDraft(d *balance.Draft) *balance.Draft {
+	data, _ := json.Marshal(d)
+	var copied balance.Draft
+	json.Unmarshal(data, &copied)
+	return &copied
+}
@@ -0,0 +1,137 @@
+package session
+
+import (
+	"lol-team-backend/balance"
+	"sort"
+	"time"
+)
+
+// Round は1試合分のチーム分け結果
+type Round struct {
+	Number    int            `json:"number"`    // 試合番号（1始まり）
+	Team1     []string       `json:"team1"`     // チーム1のプレイヤーID
+	Team2     []string       `json:"team2"`     // チーム2のプレイヤーID
+	Bench     []string       `json:"bench"`     // ベンチのプレイヤーID
+	Split     *balance.Split `json:"split"`     // チーム分けの結果
+	CreatedAt time.Time      `json:"createdAt"` // 作成日時
+}
+
+// Session は同じ集まりで続けて行う試合の履歴
+type Session struct {
+	ID          string         `json:"id"`          // セッションID
+	CreatedAt   time.Time      `json:"createdAt"`   // 作成日時
+	UpdatedAt   time.Time      `json:"updatedAt"`   // 最終更新日時
+	Rounds      []Round        `json:"rounds"`      // 試合履歴
+	BenchCounts map[string]int `json:"benchCounts"` // プレイヤーごとのベンチ回数
+}
+
+// PickBench は今回ベンチに回すプレイヤーIDを選ぶ
+// ベンチ回数が少ない順、同数なら最後にベンチだった試合が古い順（未経験が先）、
+// それも同じならロスターの末尾（後から参加したプレイヤー）を優先する
+func (s *Session) PickBench(players []balance.Player, count int) []string {
+	if count <= 0 {
+		return nil
+	}
+
+	lastBenched := make(map[string]int)
+	for _, round := range s.Rounds {
+		for _, id := range round.Bench {
+			lastBenched[id] = round.Number
+		}
+	}
+
+	order := make([]int, len(players))
+	for i := range order {
+		order[i] = len(players) - 1 - i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		pa, pb := players[order[a]].ID, players[order[b]].ID
+		if s.BenchCounts[pa] != s.BenchCounts[pb] {
+			return s.BenchCounts[pa] < s.BenchCounts[pb]
+		}
+		return lastBenched[pa] < lastBenched[pb]
+	})
+
+	bench := make([]string, 0, count)
+	for _, idx := range order[:min(count, len(order))] {
+		bench = append(bench, players[idx].ID)
+	}
+	return bench
+}
+
+// AddRound はチーム分けの結果を試合履歴に追加する
+func (s *Session) AddRound(split *balance.Split, bench []string) Round {
+	round := Round{
+		Number:    len(s.Rounds) + 1,
+		Team1:     playerIDs(split.Team1.Players),
+		Team2:     playerIDs(split.Team2.Players),
+		Bench:     bench,
+		Split:     split,
+		CreatedAt: time.Now(),
+	}
+
+	s.Rounds = append(s.Rounds, round)
+	for _, id := range bench {
+		s.BenchCounts[id]++
+	}
+	s.UpdatedAt = round.CreatedAt
+
+	return round
+}
+
+// ReplaceLastRound は直前の試合のチーム分けを差し替える（ベンチはそのまま）
+func (s *Session) ReplaceLastRound(split *balance.Split) Round {
+	last := &s.Rounds[len(s.Rounds)-1]
+	last.Team1 = playerIDs(split.Team1.Players)
+	last.Team2 = playerIDs(split.Team2.Players)
+	last.Split = split
+	last.CreatedAt = time.Now()
+	s.UpdatedAt = last.CreatedAt
+
+	return *last
+}
+
+// TeammateHistory は先頭からrounds試合分で同じチームになったプレイヤーの組と回数を返す
+func (s *Session) TeammateHistory(rounds int) []balance.TeammatePair {
+	counts := make(map[[2]string]int)
+	var order [][2]string
+
+	for _, round := range s.Rounds[:max(0, min(rounds, len(s.Rounds)))] {
+		for _, team := range [][]string{round.Team1, round.Team2} {
+			for i := range team {
+				for j := i + 1; j < len(team); j++ {
+					key := [2]string{team[i], team[j]}
+					if key[0] > key[1] {
+						key[0], key[1] = key[1], key[0]
+					}
+					if _, ok := counts[key]; !ok {
+						order = append(order, key)
+					}
+					counts[key]++
+				}
+			}
+		}
+	}
+
+	pairs := make([]balance.TeammatePair, len(order))
+	for i, key := range order {
+		pairs[i] = balance.TeammatePair{A: key[0], B: key[1], Count: counts[key]}
+	}
+	return pairs
+}
+
+// LastRound は直前の試合を返す
+func (s *Session) LastRound() (Round, bool) {
+	if len(s.Rounds) == 0 {
+		return Round{}, false
+	}
+	return s.Rounds[len(s.Rounds)-1], true
+}
+
+func playerIDs(players []balance.Player) []string {
+	ids := make([]string, len(players))
+	for i, p := range players {
+		ids[i] = p.ID
+	}
+	return ids
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sessionTTL は最終更新からセッションを保持する期間
+const sessionTTL = 24 * time.Hour
+
+// Store はセッションのインメモリ保存先
+type Store struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewStore は新しいセッションストアを作成
+func NewStore() *Store {
+	return &Store{
+		sessions: make(map[string]*Session),
+	}
+}
+
+// Create は新しいセッションを作成する
+func (s *Store) Create() (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("セッションIDの生成に失敗: %w", err)
+	}
+
+	now := time.Now()
+	session := &Session{
+		ID:          id,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		BenchCounts: make(map[string]int),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired(now)
+	s.sessions[id] = session
+
+	return session.clone(), nil
+}
+
+// Get はセッションのコピーを取得する
+func (s *Store) Get(id string) (*Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.sessions[id]
+	if !exists {
+		return nil, false
+	}
+	return session.clone(), true
+}
+
+// Update はセッションをロックした状態でfnを実行し、エラーがなければ変更を保存する
+func (s *Store) Update(id string, fn func(*Session) error) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.sessions[id]
+	if !exists {
+		return nil, fmt.Errorf("セッションが見つかりません: %s", id)
+	}
+
+	working := session.clone()
+	if err := fn(working); err != nil {
+		return nil, err
+	}
+
+	s.sessions[id] = working
+	return working.clone(), nil
+}
+
+// removeExpired は期限切れのセッションを削除する（ロック取得済みで呼ぶ）
+func (s *Store) removeExpired(now time.Time) {
+	for id, session := range s.sessions {
+		if now.Sub(session.UpdatedAt) > sessionTTL {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// clone はセッションのディープコピーを返す
+func (s *Session) clone() *Session {
+	data, _ := json.Marshal(s)
+	var copied Session
+	json.Unmarshal(data, &copied)
+	if copied.BenchCounts == nil {
+		copied.BenchCounts = make(map[string]int)
+	}
+	return &copied
+}
+
+// newID はランダムなセッションIDを生成する
+func newID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
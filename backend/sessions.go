@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"lol-team-backend/balance"
+	"lol-team-backend/ledger"
+	"lol-team-backend/session"
+	"net/http"
+)
+
+type SessionRequest struct {
+	SessionID string `json:"sessionId"`
+}
+
+type SessionDivideRequest struct {
+	SessionID string           `json:"sessionId"`
+	Players   []balance.Player `json:"players"`
+	Reroll    bool             `json:"reroll"` // trueの場合は直前の試合をベンチそのままで組み直す
+	balance.Options
+	ledger.Source
+}
+
+type SessionDivideResponse struct {
+	SessionID    string           `json:"sessionId"`
+	Round        session.Round    `json:"round"`
+	Alternatives []*balance.Split `json:"alternatives"`
+}
+
+var sessionStore = session.NewStore()
+
+func createSessionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s, err := sessionStore.Create()
+	if err != nil {
+		fmt.Printf("ERROR: Failed to create session: %v\n", err)
+		http.Error(w, fmt.Sprintf("Failed to create session: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Printf("INFO: Session created - ID: %s\n", s.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+func getSessionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s, ok := sessionStore.Get(req.SessionID)
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+func divideSessionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req SessionDivideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Received session divide request - SessionID: %s, Players: %d, Reroll: %v\n",
+		req.SessionID, len(req.Players), req.Reroll)
+
+	lobbySize := balance.TeamSize * 2
+	if len(req.Players) < lobbySize || len(req.Players) >= lobbySize*2 {
+		http.Error(w, fmt.Sprintf("Players must be between %d and %d", lobbySize, lobbySize*2-1), http.StatusBadRequest)
+		return
+	}
+
+	players, ok := applyRatingSource(w, req.Players, req.Source)
+	if !ok {
+		return
+	}
+
+	var round session.Round
+	var alternatives []*balance.Split
+
+	_, err := sessionStore.Update(req.SessionID, func(s *session.Session) error {
+		opts := req.Options
+
+		// 組み直しの場合は差し替える直前の試合を履歴に含めない
+		history := len(s.Rounds)
+		if req.Reroll {
+			history--
+		}
+		if opts.VarietyWeight > 0 {
+			opts.TeammateHistory = append(opts.TeammateHistory, s.TeammateHistory(history)...)
+		}
+
+		var bench []string
+		if req.Reroll {
+			last, ok := s.LastRound()
+			if !ok {
+				return fmt.Errorf("組み直す試合がありません")
+			}
+			bench = last.Bench
+			opts.PreviousTeam1 = last.Team1
+		} else {
+			bench = s.PickBench(players, len(players)-lobbySize)
+		}
+
+		benched := make(map[string]bool, len(bench))
+		for _, id := range bench {
+			benched[id] = true
+		}
+		active := make([]balance.Player, 0, lobbySize)
+		for _, p := range players {
+			if !benched[p.ID] {
+				active = append(active, p)
+			}
+		}
+
+		splits, err := balance.DivideTopK(active, opts)
+		if err != nil {
+			return err
+		}
+
+		if req.Reroll {
+			round = s.ReplaceLastRound(splits[0])
+		} else {
+			round = s.AddRound(splits[0], bench)
+		}
+		alternatives = splits[1:]
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("ERROR: Failed to divide session teams: %v\n", err)
+		http.Error(w, fmt.Sprintf("Failed to divide teams: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Session round divided - SessionID: %s, Round: %d, Bench: %v\n",
+		req.SessionID, round.Number, round.Bench)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SessionDivideResponse{
+		SessionID:    req.SessionID,
+		Round:        round,
+		Alternatives: alternatives,
+	})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"lol-team-backend/balance"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// sessionTestPlayers はセッションのテスト用のロスター（同じレーティングの組を含む）
+func sessionTestPlayers() []balance.Player {
+	fill := []string{"FILL"}
+	return []balance.Player{
+		{ID: "ahri", Rating: 1800, PreferredRoles: fill},
+		{ID: "garen", Rating: 1200, PreferredRoles: fill},
+		{ID: "leesin", Rating: 1600, PreferredRoles: fill},
+		{ID: "jinx", Rating: 1400, PreferredRoles: fill},
+		{ID: "thresh", Rating: 1000, PreferredRoles: fill},
+		{ID: "zed", Rating: 1700, PreferredRoles: fill},
+		{ID: "darius", Rating: 1200, PreferredRoles: fill},
+		{ID: "viego", Rating: 1500, PreferredRoles: fill},
+		{ID: "caitlyn", Rating: 1400, PreferredRoles: fill},
+		{ID: "lulu", Rating: 1100, PreferredRoles: fill},
+	}
+}
+
+func teamKey(ids []string) string {
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+func divideSession(t *testing.T, req SessionDivideRequest) SessionDivideResponse {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	divideSessionHandler(rec, httptest.NewRequest(http.MethodPost, "/api/sessions/divide", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp SessionDivideResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestSessionRerollChangesTeams(t *testing.T) {
+	s, err := sessionStore.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	players := sessionTestPlayers()
+
+	for seed := int64(1); seed <= 30; seed++ {
+		req := SessionDivideRequest{SessionID: s.ID, Players: players}
+		req.Seed = seed
+		first := divideSession(t, req)
+
+		req.Reroll = true
+		reroll := divideSession(t, req)
+
+		if reroll.Round.Number != first.Round.Number {
+			t.Fatalf("seed %d: reroll created round %d, want to replace round %d", seed, reroll.Round.Number, first.Round.Number)
+		}
+		previous := teamKey(first.Round.Team1)
+		if teamKey(reroll.Round.Team1) == previous || teamKey(reroll.Round.Team2) == previous {
+			t.Fatalf("seed %d: reroll kept the previous teams %v / %v", seed, first.Round.Team1, first.Round.Team2)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lol-team-backend/balance"
+	"lol-team-backend/ledger"
+	"net/http"
+)
+
+type TeamDivideRequest struct {
+	Players []balance.Player `json:"players"`
+	balance.Options
+	ledger.Source
+}
+
+type TeamDivideResponse struct {
+	*balance.Split
+	Alternatives []*balance.Split `json:"alternatives"`
+}
+
+type LobbyDivideRequest struct {
+	Players []balance.Player `json:"players"`
+	balance.LobbyOptions
+	ledger.Source
+}
+
+type ArenaDivideRequest struct {
+	Players []balance.Player `json:"players"`
+	balance.ArenaOptions
+	ledger.Source
+}
+
+type WinPredictRequest struct {
+	Team1 []int   `json:"team1"`
+	Team2 []int   `json:"team2"`
+	Scale float64 `json:"scale"`
+}
+
+func divideTeamsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TeamDivideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Received team divide request - Players: %d, Mode: %s\n", len(req.Players), req.Mode)
+
+	players, ok := applyRatingSource(w, req.Players, req.Source)
+	if !ok {
+		return
+	}
+
+	splits, err := balance.DivideTopK(players, req.Options)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to divide teams: %v\n", err)
+		status := http.StatusBadRequest
+		var constraintErr *balance.ConstraintError
+		if errors.As(err, &constraintErr) {
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, fmt.Sprintf("Failed to divide teams: %v", err), status)
+		return
+	}
+
+	fmt.Printf("INFO: Teams divided - Diff: %d, LaneGap: %d, Alternatives: %d, Seed: %d\n",
+		splits[0].Diff, splits[0].LaneGap, len(splits)-1, splits[0].Seed)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TeamDivideResponse{
+		Split:        splits[0],
+		Alternatives: splits[1:],
+	})
+}
+
+func divideLobbiesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LobbyDivideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Received lobby divide request - Players: %d, TimeBudgetMs: %d, Iterations: %d\n",
+		len(req.Players), req.TimeBudgetMs, req.Iterations)
+
+	players, ok := applyRatingSource(w, req.Players, req.Source)
+	if !ok {
+		return
+	}
+
+	result, err := balance.DivideLobbies(players, req.LobbyOptions)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to divide lobbies: %v\n", err)
+		http.Error(w, fmt.Sprintf("Failed to divide lobbies: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Lobbies divided - Lobbies: %d, Bench: %d, Spread: %.1f, Iterations: %d, TimedOut: %v, Seed: %d\n",
+		len(result.Lobbies), len(result.Bench), result.Spread, result.Iterations, result.TimedOut, result.Seed)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func divideArenaHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ArenaDivideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Received arena divide request - Players: %d, TeamSize: %d, TeamCount: %d\n",
+		len(req.Players), req.TeamSize, req.TeamCount)
+
+	players, ok := applyRatingSource(w, req.Players, req.Source)
+	if !ok {
+		return
+	}
+
+	result, err := balance.DivideArena(players, req.ArenaOptions)
+	if err != nil {
+		fmt.Printf("ERROR: Failed to divide arena teams: %v\n", err)
+		http.Error(w, fmt.Sprintf("Failed to divide arena teams: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("INFO: Arena teams divided - Teams: %d, Spread: %d, Iterations: %d\n",
+		len(result.Teams), result.Spread, result.Iterations)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func predictWinHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req WinPredictRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Team1) == 0 || len(req.Team2) == 0 {
+		http.Error(w, "Both teams must have at least one rating", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance.WinProbability(req.Team1, req.Team2, req.Scale))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"lol-team-backend/rating"
+	"net/http"
+	"os"
+)
+
+type TierConvertRequest struct {
+	Ratings []int         `json:"ratings"` // ティア情報に変換するレーティング
+	Ranks   []rating.Rank `json:"ranks"`   // レーティングに変換するティア情報
+}
+
+type TierConvertResponse struct {
+	Tiers   []rating.Rank `json:"tiers"`   // Ratingsの順のティア情報
+	Ratings []int         `json:"ratings"` // Ranksの順のレーティング
+	Scale   rating.Scale  `json:"scale"`   // 変換に使った対応
+}
+
+type TierTableResponse struct {
+	Steps []rating.Step `json:"steps"` // ティア・ディビジョンの区切りとレーティング（低い順）
+	Scale rating.Scale  `json:"scale"` // 変換に使う対応
+}
+
+// loadRatingScale は環境変数RATING_SCALE（JSON。指定した項目だけデフォルトを上書き）でレーティングの対応を設定する
+// 例: RATING_SCALE='{"apexSpan":1500,"unranked":1000}'
+func loadRatingScale() error {
+	raw := os.Getenv("RATING_SCALE")
+	if raw == "" {
+		return nil
+	}
+
+	scale := rating.DefaultScale
+	if err := json.Unmarshal([]byte(raw), &scale); err != nil {
+		return fmt.Errorf("invalid RATING_SCALE: %w", err)
+	}
+	if err := scale.Validate(); err != nil {
+		return fmt.Errorf("invalid RATING_SCALE: %w", err)
+	}
+	rating.DefaultScale = scale
+	return nil
+}
+
+// convertTierHandler はレーティングとティア情報をバックエンドと同じ対応で相互に変換する
+// GETの場合は区切りの一覧を返す（フロントエンドは一度取得して表示や入力の変換に使う）
+func convertTierHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TierTableResponse{
+			Steps: rating.DefaultScale.Steps(),
+			Scale: rating.DefaultScale,
+		})
+		return
+	}
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TierConvertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		fmt.Printf("ERROR: Invalid request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	resp := TierConvertResponse{
+		Tiers:   make([]rating.Rank, len(req.Ratings)),
+		Ratings: make([]int, len(req.Ranks)),
+		Scale:   rating.DefaultScale,
+	}
+	for i, r := range req.Ratings {
+		resp.Tiers[i] = rating.ToTier(r)
+	}
+	for i, rank := range req.Ranks {
+		resp.Ratings[i] = rating.FromTier(rank.Tier, rank.Rank, rank.LP)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"lol-team-backend/rating"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConvertTierHandler(t *testing.T) {
+	req := TierConvertRequest{
+		Ratings: []int{0, 1250, rating.DefaultScale.ApexBase() + 500},
+		Ranks: []rating.Rank{
+			{Tier: "GOLD", Rank: "II", LP: 50},
+			{Tier: "CHALLENGER", Rank: "I", LP: 1500},
+			{Tier: "UNRANKED"},
+		},
+	}
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	convertTierHandler(rec, httptest.NewRequest(http.MethodPost, "/api/rating/tier", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp TierConvertResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, r := range req.Ratings {
+		if want := rating.ToTier(r); resp.Tiers[i] != want {
+			t.Errorf("tier for %d = %+v, want %+v", r, resp.Tiers[i], want)
+		}
+	}
+	for i, rank := range req.Ranks {
+		if want := rating.FromTier(rank.Tier, rank.Rank, rank.LP); resp.Ratings[i] != want {
+			t.Errorf("rating for %+v = %d, want %d", rank, resp.Ratings[i], want)
+		}
+	}
+	if resp.Scale != rating.DefaultScale {
+		t.Errorf("scale = %+v, want the default scale", resp.Scale)
+	}
+
+	rec = httptest.NewRecorder()
+	convertTierHandler(rec, httptest.NewRequest(http.MethodPut, "/api/rating/tier", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestTierTable(t *testing.T) {
+	rec := httptest.NewRecorder()
+	convertTierHandler(rec, httptest.NewRequest(http.MethodGet, "/api/rating/tier", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp TierTableResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Scale != rating.DefaultScale {
+		t.Errorf("scale = %+v, want the default scale", resp.Scale)
+	}
+	want := rating.DefaultScale.Steps()
+	if len(resp.Steps) != len(want) {
+		t.Fatalf("steps = %d, want %d", len(resp.Steps), len(want))
+	}
+	for i := range want {
+		if resp.Steps[i] != want[i] {
+			t.Errorf("step %d = %+v, want %+v", i, resp.Steps[i], want[i])
+		}
+	}
+}
+
+func TestLoadRatingScale(t *testing.T) {
+	saved := rating.DefaultScale
+	t.Cleanup(func() { rating.DefaultScale = saved })
+
+	t.Setenv("RATING_SCALE", `{"apexSpan":1500,"unranked":1000}`)
+	if err := loadRatingScale(); err != nil {
+		t.Fatal(err)
+	}
+	want := saved
+	want.ApexSpan = 1500
+	want.Unranked = 1000
+	if rating.DefaultScale != want {
+		t.Errorf("scale = %+v, want %+v", rating.DefaultScale, want)
+	}
+
+	for _, raw := range []string{`{"divisionStep":0}`, `not json`} {
+		rating.DefaultScale = saved
+		t.Setenv("RATING_SCALE", raw)
+		if err := loadRatingScale(); err == nil {
+			t.Errorf("RATING_SCALE=%s: expected an error", raw)
+		}
+		if rating.DefaultScale != saved {
+			t.Errorf("RATING_SCALE=%s changed the scale to %+v", raw, rating.DefaultScale)
+		}
+	}
+}
@@ -93,50 +93,35 @@ const RoleIcon = ({ role, size = 24 }: { role: string; size?: number }) => {
   return icons[role] || null;
 };
 
-// ティアからレートへの変換(簡易版)
-const ratingToTier = (rating) => {
-  if (rating >= 3600) return { tier: "CHALLENGER", rank: "I" };
-  if (rating >= 3200) return { tier: "GRANDMASTER", rank: "I" };
-  if (rating >= 2800) return { tier: "MASTER", rank: "I" };
+// バックエンドのURL
+const API_BASE_URL = "https://lol-team-backend.onrender.com";
 
-  const tierRanges = [
-    { min: 2400, tier: "DIAMOND" },
-    { min: 2000, tier: "EMERALD" },
-    { min: 1600, tier: "PLATINUM" },
-    { min: 1200, tier: "GOLD" },
-    { min: 800, tier: "SILVER" },
-    { min: 400, tier: "BRONZE" },
-    { min: 0, tier: "IRON" },
-  ];
+// レートとティアの対応表(チーム分けと同じ対応を使うためバックエンドから一度だけ取得する)
+let tierTablePromise = null;
 
-  for (const range of tierRanges) {
-    if (rating >= range.min) {
-      const withinTier = rating - range.min;
-      if (withinTier >= 300) return { tier: range.tier, rank: "I" };
-      if (withinTier >= 200) return { tier: range.tier, rank: "II" };
-      if (withinTier >= 100) return { tier: range.tier, rank: "III" };
-      return { tier: range.tier, rank: "IV" };
-    }
+const loadTierTable = () => {
+  if (!tierTablePromise) {
+    tierTablePromise = fetch(`${API_BASE_URL}/api/rating/tier`)
+      .then((response) => {
+        if (!response.ok) {
+          throw new Error("ランクの対応表の取得に失敗しました");
+        }
+        return response.json();
+      })
+      .then((data) => data.steps)
+      .catch((error) => {
+        // 失敗したら次回に再取得する
+        tierTablePromise = null;
+        throw error;
+      });
   }
-
-  return { tier: "IRON", rank: "IV" };
+  return tierTablePromise;
 };
 
-const tierToRating = (tier, rank, lp) => {
-  const tierValues = {
-    IRON: 0,
-    BRONZE: 400,
-    SILVER: 800,
-    GOLD: 1200,
-    PLATINUM: 1600,
-    EMERALD: 2000,
-    DIAMOND: 2400,
-    MASTER: 2800,
-    GRANDMASTER: 3200,
-    CHALLENGER: 3600,
-  };
-  const rankValues = { IV: 0, III: 100, II: 200, I: 300 };
-  return (tierValues[tier] || 0) + (rankValues[rank] || 0) + (lp || 0);
+// ティア・ディビジョンの区切り(0LPのレート。マスター以上は目安のLP)
+const findTierStep = (steps, tier, rank) => {
+  const step = steps.find((s) => s.tier === tier && s.rank === rank);
+  return step ? step : steps.find((s) => s.tier === tier);
 };
 
 // Riot APIからランク情報を取得
@@ -179,85 +164,46 @@ const mockFetchRank = async (summonerName, tag) => {
     "DIAMOND",
   ];
   const ranks = ["IV", "III", "II", "I"];
+  const steps = await loadTierTable();
   const tier = tiers[Math.floor(Math.random() * tiers.length)];
   const rank = ranks[Math.floor(Math.random() * ranks.length)];
-  const lp = Math.floor(Math.random() * 100);
-  return { tier, rank, lp, rating: tierToRating(tier, rank, lp) };
+  const step = findTierStep(steps, tier, rank);
+  return { tier, rank, lp: step.lp, rating: step.rating };
 };
 
-// チーム分けアルゴリズム
-const divideTeams = (players, previousTeam1 = null) => {
-  let bestDiff = Infinity;
-  let bestTeams = null;
-
-  const n = players.length;
-  for (let mask = 0; mask < 1 << n; mask++) {
-    if (countBits(mask) !== 5) continue;
-
-    const team1 = [];
-    const team2 = [];
-
-    for (let i = 0; i < n; i++) {
-      if (mask & (1 << i)) team1.push(players[i]);
-      else team2.push(players[i]);
-    }
-
-    // 前回のチーム1と同じ構成をスキップ
-    if (previousTeam1) {
-      const team1IDs = team1.map((p) => p.id).sort();
-      const prevTeam1IDs = previousTeam1.map((p) => p.id).sort();
-      if (JSON.stringify(team1IDs) === JSON.stringify(prevTeam1IDs)) {
-        continue;
-      }
-    }
-
-    const sum1 = team1.reduce((s, p) => s + p.rating, 0);
-    const sum2 = team2.reduce((s, p) => s + p.rating, 0);
-    const diff = Math.abs(sum1 - sum2);
+// チーム分け(組み合わせとロール配分はバックエンドで決める)
+const divideTeams = async (players, previousTeam1 = null) => {
+  const response = await fetch(`${API_BASE_URL}/api/teams/divide`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({
+      players: players.map((p) => ({
+        id: String(p.id),
+        name: `${p.summonerName}#${p.tag}`,
+        rating: p.rating,
+        preferredRoles: p.preferredRoles,
+      })),
+      previousTeam1: previousTeam1
+        ? previousTeam1.map((p) => String(p.id))
+        : undefined,
+    }),
+  });
 
-    if (diff < bestDiff) {
-      bestDiff = diff;
-      bestTeams = { team1, team2 };
-    }
+  if (!response.ok) {
+    throw new Error("チーム分けに失敗しました");
   }
 
-  return bestTeams;
+  return response.json();
 };
 
-const countBits = (n) => {
-  let count = 0;
-  while (n) {
-    count += n & 1;
-    n >>= 1;
-  }
-  return count;
-};
-
-// ロール配分
-const assignRoles = (team) => {
-  const roleOrder = ["TOP", "JUNGLE", "MID", "ADC", "SUPPORT"];
-  const availableRoles = [...roleOrder];
-  const assignments = [];
-
-  const sorted = [...team].sort(
-    (a, b) => a.preferredRoles.length - b.preferredRoles.length
-  );
-
-  sorted.forEach((player) => {
-    const possibleRoles = availableRoles.filter((r) =>
-      player.preferredRoles.includes(r)
-    );
-    const assignedRole =
-      possibleRoles.length > 0 ? possibleRoles[0] : availableRoles[0];
-    assignments.push({ ...player, assignedRole });
-    availableRoles.splice(availableRoles.indexOf(assignedRole), 1);
-  });
-
-  return assignments.sort(
-    (a, b) =>
-      roleOrder.indexOf(a.assignedRole) - roleOrder.indexOf(b.assignedRole)
-  );
-};
+// バックエンドのロール配分(ロール順)を表示用のプレイヤーに戻す
+const withAssignedRoles = (team, players) =>
+  team.roles.map((assignment) => ({
+    ...players.find((p) => String(p.id) === assignment.playerId),
+    assignedRole: assignment.role,
+  }));
 
 export default function LoLTeamMaker() {
   const [players, setPlayers] = useState([]);
@@ -313,8 +259,8 @@ export default function LoLTeamMaker() {
 
   // 全ロール選択/解除ボタン
   const toggleAllRoles = (playerId) => {
-    setPlayers(
-      players.map((player) => {
+    setPlayers((prev) =>
+      prev.map((player) => {
         if (player.id === playerId) {
           const allSelected = player.preferredRoles.length === ROLES.length;
           return {
@@ -330,8 +276,8 @@ export default function LoLTeamMaker() {
 
   // プレイヤーのロールを切り替える関数
   const togglePlayerRole = (playerId, role) => {
-    setPlayers(
-      players.map((player) => {
+    setPlayers((prev) =>
+      prev.map((player) => {
         if (player.id === playerId) {
           const hasRole = player.preferredRoles.includes(role);
           const newRoles = hasRole
@@ -345,17 +291,24 @@ export default function LoLTeamMaker() {
     setResult(null);
   };
   // ✅ ここに追加
-  const changePlayerRank = (playerId, newTier, newRank) => {
-    setPlayers(
-      players.map((player) => {
+  const changePlayerRank = async (playerId, newTier, newRank) => {
+    let step;
+    try {
+      step = findTierStep(await loadTierTable(), newTier, newRank);
+    } catch (error) {
+      alert(error.message);
+      return;
+    }
+    // 取得を待つ間に他の変更があっても上書きしないよう最新の状態に適用する
+    setPlayers((prev) =>
+      prev.map((player) => {
         if (player.id === playerId) {
-          const newRating = tierToRating(newTier, newRank, 0);
           return {
             ...player,
             tier: newTier,
             rank: newRank,
-            lp: 0,
-            rating: newRating,
+            lp: step.lp,
+            rating: step.rating,
           };
         }
         return player;
@@ -364,7 +317,7 @@ export default function LoLTeamMaker() {
     setResult(null);
   };
   const removePlayer = (id) => {
-    setPlayers(players.filter((p) => p.id !== id));
+    setPlayers((prev) => prev.filter((p) => p.id !== id));
     setResult(null);
   };
   const addPlayer = async () => {
@@ -464,7 +417,7 @@ export default function LoLTeamMaker() {
 
     // 成功したプレイヤーを追加
     if (successList.length > 0) {
-      setPlayers([...players, ...successList.map((s) => s.player)]);
+      setPlayers((prev) => [...prev, ...successList.map((s) => s.player)]);
     }
 
     // 結果を表示
@@ -485,21 +438,26 @@ export default function LoLTeamMaker() {
     setProcessedCount(0);
     setTotalCount(0);
   };
-  const createTeams = () => {
+  const createTeams = async () => {
     if (players.length !== 10) {
       alert("10人揃ってから実行してください");
       return;
     }
 
-    const teams = divideTeams(players, result?.blueTeam);
-    const team1WithRoles = assignRoles(teams.team1);
-    const team2WithRoles = assignRoles(teams.team2);
-
-    const avgRating1 = team1WithRoles.reduce((s, p) => s + p.rating, 0) / 5;
-    const avgRating2 = team2WithRoles.reduce((s, p) => s + p.rating, 0) / 5;
+    let split;
+    try {
+      split = await divideTeams(players, result?.blueTeam);
+    } catch (error) {
+      alert(error.message);
+      return;
+    }
 
-    const avgTier1 = ratingToTier(Math.round(avgRating1));
-    const avgTier2 = ratingToTier(Math.round(avgRating2));
+    const team1WithRoles = withAssignedRoles(split.team1, players);
+    const team2WithRoles = withAssignedRoles(split.team2, players);
+    const avgRating1 = split.team1.averageRating;
+    const avgRating2 = split.team2.averageRating;
+    const avgTier1 = split.team1.averageTier;
+    const avgTier2 = split.team2.averageTier;
 
     setResult({
       blueTeam: team1WithRoles,